@@ -0,0 +1,381 @@
+package mox
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	ErrMissingPathParam = errors.New("missing path param")
+)
+
+var (
+	pathTemplateParamRegexp = regexp.MustCompile(`/[:*](\w+)|\{(\w+)\}`)
+	textMarshalerType       = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	fileHeaderType          = reflect.TypeOf((*multipart.FileHeader)(nil))
+)
+
+// EncodeForm encode the struct into url.Values, it is the reverse of OptionFormBinding and OptionQueryBinding.
+//   - the name is from form tag, then field name, `form:"-"` is skipped.
+//   - absent option and nil pointer are skipped, slice is encoded as multiple values.
+//   - time.Time is formatted by time_format tag, default RFC3339, time.Duration by String.
+func EncodeForm(obj any) (url.Values, error) {
+	v, err := structValue(obj)
+	if err != nil {
+		return nil, err
+	}
+	values := url.Values{}
+	for _, field := range tagFields(v.Type(), "form") {
+		if err := encodeFormField(values, field, v.FieldByIndex(field.Index)); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+func encodeFormField(values url.Values, field tagField, value reflect.Value) error {
+	strs, ok, err := formStrings(value, field.Field)
+	if err != nil || !ok {
+		return err
+	}
+	values[field.Name] = append(values[field.Name], strs...)
+	return nil
+}
+
+// formStrings format the value of field, return false if it is absent or nil.
+func formStrings(value reflect.Value, field reflect.StructField) ([]string, bool, error) {
+	if info, ok := OptionOf(value.Type()); ok {
+		v, ok := info.get(value)
+		if !ok {
+			return nil, false, nil
+		}
+		value = v
+	}
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil, false, nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		strs := make([]string, 0, value.Len())
+		for i := range value.Len() {
+			item, ok, err := formStrings(value.Index(i), field)
+			if err != nil {
+				return nil, false, err
+			}
+			if ok {
+				strs = append(strs, item...)
+			}
+		}
+		return strs, true, nil
+	}
+	str, err := formatFormValue(value, field)
+	if err != nil {
+		return nil, false, err
+	}
+	return []string{str}, true, nil
+}
+
+func formatFormValue(value reflect.Value, field reflect.StructField) (string, error) {
+	switch v := value.Interface().(type) {
+	case time.Time:
+		layout := field.Tag.Get("time_format")
+		switch layout {
+		case "":
+			layout = time.RFC3339
+		case "unix":
+			return strconv.FormatInt(v.Unix(), 10), nil
+		case "unixmilli":
+			return strconv.FormatInt(v.UnixMilli(), 10), nil
+		case "unixmicro":
+			return strconv.FormatInt(v.UnixMicro(), 10), nil
+		case "unixnano":
+			return strconv.FormatInt(v.UnixNano(), 10), nil
+		}
+		return v.Format(layout), nil
+	case time.Duration:
+		return v.String(), nil
+	}
+	if value.Type().Implements(textMarshalerType) {
+		b, err := value.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(value.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("%w: %s is %s", ErrNotSupportKind, field.Name, value.Kind())
+	}
+}
+
+// NewRequest create the request from dto, the content type of body is decided by dto, see NewRequestWithContentType.
+//   - form body if there is no json field, multipart if there is *multipart.FileHeader field.
+//   - json body otherwise.
+func NewRequest(method string, pathTemplate string, dto any) (*http.Request, error) {
+	return NewRequestWithContentType(method, pathTemplate, dto, "")
+}
+
+// NewRequestWithContentType create the request from dto, it is the reverse of binding, absent option and nil pointer are skipped.
+//   - uri tag: replace the param of pathTemplate, like /users/:id, /files/*path or /users/{id}.
+//   - header tag: header.
+//   - GET, HEAD and DELETE: other fields are query, same as EncodeForm.
+//   - other methods: form tag fields are form body, json tag or untagged fields are json body.
+//   - dto is not struct: json body of dto.
+//   - json body is encoded by OptionJSON, so the nested absent option with omitempty is omitted instead of null.
+//
+// contentType is gin.MIMEJSON, gin.MIMEPOSTForm or gin.MIMEMultipartPOSTForm, empty to decide by dto.
+func NewRequestWithContentType(method string, pathTemplate string, dto any, contentType string) (*http.Request, error) {
+	method = strings.ToUpper(method)
+	hasBody := method != http.MethodGet && method != http.MethodHead && method != http.MethodDelete
+	v := reflect.ValueOf(dto)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		if !v.IsValid() || (v.Kind() == reflect.Ptr && v.IsNil()) || !hasBody {
+			return http.NewRequest(method, pathTemplate, nil)
+		}
+		b, err := OptionJSON.Marshal(dto)
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequest(method, pathTemplate, bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", gin.MIMEJSON)
+		return req, nil
+	}
+
+	params := map[string]string{}
+	header := http.Header{}
+	query := url.Values{}
+	form := url.Values{}
+	files := map[string][]*multipart.FileHeader{}
+	body := map[string]any{}
+	for _, field := range tagFields(v.Type(), "uri", "header", "form", "json") {
+		value := v.FieldByIndex(field.Index)
+		tag := field.Field.Tag
+		formTag, isForm := tag.Lookup("form")
+		jsonTag, isJSON := tag.Lookup("json")
+		switch {
+		case tag.Get("uri") != "":
+			strs, ok, err := formStrings(value, field.Field)
+			if err != nil {
+				return nil, err
+			}
+			if ok && len(strs) > 0 {
+				params[tagFieldOf(field, "uri").Name] = strs[0]
+			}
+		case tag.Get("header") != "":
+			if err := encodeFormField(url.Values(header), tagFieldOf(field, "header"), value); err != nil {
+				return nil, err
+			}
+		case !hasBody:
+			if formTag == "-" {
+				continue
+			}
+			if err := encodeFormField(query, tagFieldOf(field, "form"), value); err != nil {
+				return nil, err
+			}
+		default:
+			if isForm && formTag != "-" {
+				if fileHeaders, ok := formFiles(value); ok {
+					if len(fileHeaders) > 0 {
+						files[tagFieldOf(field, "form").Name] = fileHeaders
+					}
+				} else if err := encodeFormField(form, tagFieldOf(field, "form"), value); err != nil {
+					return nil, err
+				}
+			}
+			if (isJSON && jsonTag != "-") || !isForm {
+				if value, ok := jsonBodyValue(value); ok {
+					body[tagFieldOf(field, "json").Name] = value
+				}
+			}
+		}
+	}
+
+	path, err := expandPathTemplate(pathTemplate, params)
+	if err != nil {
+		return nil, err
+	}
+	if len(query) > 0 {
+		if strings.Contains(path, "?") {
+			path += "&" + query.Encode()
+		} else {
+			path += "?" + query.Encode()
+		}
+	}
+
+	if contentType == "" && hasBody {
+		switch {
+		case len(files) > 0:
+			contentType = gin.MIMEMultipartPOSTForm
+		case len(body) > 0:
+			contentType = gin.MIMEJSON
+		case len(form) > 0:
+			contentType = gin.MIMEPOSTForm
+		}
+	}
+	var reader io.Reader
+	switch contentType {
+	case "":
+	case gin.MIMEJSON:
+		b, err := OptionJSON.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(b)
+	case gin.MIMEPOSTForm:
+		reader = strings.NewReader(form.Encode())
+	case gin.MIMEMultipartPOSTForm:
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		if err := writeMultipart(writer, form, files); err != nil {
+			return nil, err
+		}
+		reader, contentType = &buf, writer.FormDataContentType()
+	default:
+		return nil, fmt.Errorf("%w: content type %s", ErrNotSupportKind, contentType)
+	}
+
+	req, err := http.NewRequest(method, path, reader)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	if reader != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return req, nil
+}
+
+// expandPathTemplate replace the params of path with escaped value.
+func expandPathTemplate(path string, params map[string]string) (string, error) {
+	var err error
+	path = pathTemplateParamRegexp.ReplaceAllStringFunc(path, func(s string) string {
+		match := pathTemplateParamRegexp.FindStringSubmatch(s)
+		name, prefix := match[1], "/"
+		if name == "" {
+			name, prefix = match[2], ""
+		}
+		value, ok := params[name]
+		if !ok {
+			if err == nil {
+				err = fmt.Errorf("%w: %s", ErrMissingPathParam, name)
+			}
+			return s
+		}
+		if strings.HasPrefix(s, "/*") {
+			// catch-all param keep the slash
+			return prefix + strings.TrimPrefix((&url.URL{Path: value}).EscapedPath(), "/")
+		}
+		return prefix + url.PathEscape(value)
+	})
+	return path, err
+}
+
+// jsonBodyValue return the value of field in json body, false if absent option or nil pointer.
+func jsonBodyValue(value reflect.Value) (any, bool) {
+	if info, ok := OptionOf(value.Type()); ok {
+		v, ok := info.get(value)
+		if !ok {
+			return nil, false
+		}
+		return v.Interface(), true
+	}
+	if value.Kind() == reflect.Ptr && value.IsNil() {
+		return nil, false
+	}
+	return value.Interface(), true
+}
+
+// formFiles return the files if value is *multipart.FileHeader or slice of it.
+func formFiles(value reflect.Value) ([]*multipart.FileHeader, bool) {
+	switch v := value.Interface().(type) {
+	case *multipart.FileHeader:
+		if v == nil {
+			return nil, true
+		}
+		return []*multipart.FileHeader{v}, true
+	case []*multipart.FileHeader:
+		return v, true
+	}
+	return nil, value.Type() == fileHeaderType
+}
+
+func writeMultipart(writer *multipart.Writer, form url.Values, files map[string][]*multipart.FileHeader) error {
+	for name, values := range form {
+		for _, value := range values {
+			if err := writer.WriteField(name, value); err != nil {
+				return err
+			}
+		}
+	}
+	for name, fileHeaders := range files {
+		for _, fileHeader := range fileHeaders {
+			if err := writeMultipartFile(writer, name, fileHeader); err != nil {
+				return err
+			}
+		}
+	}
+	return writer.Close()
+}
+
+func writeMultipartFile(writer *multipart.Writer, name string, fileHeader *multipart.FileHeader) error {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	part, err := writer.CreateFormFile(name, fileHeader.Filename)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(part, file)
+	return err
+}
+
+// structValue return the struct value of obj, obj is struct or pointer to struct.
+func structValue(obj any) (reflect.Value, error) {
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}, fmt.Errorf("%w: %T", ErrOnlyStruct, obj)
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("%w: kind=%s", ErrOnlyStruct, v.Kind())
+	}
+	return v, nil
+}
@@ -0,0 +1,187 @@
+package mox
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+)
+
+type ClientQueryDto struct {
+	ID      int64                `uri:"id"`
+	Path    string               `uri:"path"`
+	Token   mo.Option[string]    `header:"X-Token"`
+	Name    mo.Option[string]    `form:"name"`
+	Nick    mo.Option[string]    `form:"nick"`
+	IDs     mo.Option[[]int64]   `form:"ids"`
+	Since   mo.Option[time.Time] `form:"since" time_format:"2006-01-02"`
+	Timeout time.Duration        `form:"timeout"`
+	Page    int
+	Ignore  string `form:"-"`
+}
+
+type ClientBodyDto struct {
+	ID      int64              `uri:"id"`
+	Name    mo.Option[string]  `json:"name"`
+	Email   mo.Option[*string] `json:"email"`
+	Nick    mo.Option[string]  `json:"nick"`
+	Address struct {
+		City string `json:"city"`
+	} `json:"address"`
+}
+
+type ClientFormDto struct {
+	Name mo.Option[string]     `form:"name"`
+	Nick mo.Option[string]     `form:"nick"`
+	Tags []string              `form:"tags"`
+	File *multipart.FileHeader `form:"file"`
+}
+
+func TestEncodeForm(t *testing.T) {
+	values, err := EncodeForm(&ClientQueryDto{
+		Name:    mo.Some("sb"),
+		IDs:     mo.Some([]int64{1, 2}),
+		Since:   mo.Some(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)),
+		Timeout: time.Second,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "ID=0&Page=0&Path=&ids=1&ids=2&name=sb&since=2024-01-02&timeout=1s", values.Encode())
+
+	_, err = EncodeForm(1)
+	require.ErrorIs(t, err, ErrOnlyStruct)
+	_, err = EncodeForm(struct {
+		M map[string]string `form:"m"`
+	}{M: map[string]string{}})
+	require.ErrorIs(t, err, ErrNotSupportKind)
+}
+
+func TestNewRequest(t *testing.T) {
+	req, err := NewRequest(http.MethodGet, "http://localhost:8080/users/:id/files/*path", ClientQueryDto{
+		ID:    1,
+		Path:  "a b/c.txt",
+		Token: mo.Some("token"),
+		Name:  mo.Some("sb"),
+		IDs:   mo.Some([]int64{1, 2}),
+		Page:  2,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "http://localhost:8080/users/1/files/a%20b/c.txt?Page=2&ids=1&ids=2&name=sb&timeout=0s", req.URL.String())
+	require.Equal(t, "token", req.Header.Get("X-Token"))
+	require.Nil(t, req.Body)
+
+	_, err = NewRequest(http.MethodGet, "/users/{user_id}", ClientQueryDto{})
+	require.ErrorIs(t, err, ErrMissingPathParam)
+
+	email := "sb@example.com"
+	req, err = NewRequest(http.MethodPatch, "/users/{id}", &ClientBodyDto{ID: 1, Name: mo.Some("sb"), Email: mo.Some[*string](nil)})
+	require.NoError(t, err)
+	require.Equal(t, "/users/1", req.URL.String())
+	require.Equal(t, gin.MIMEJSON, req.Header.Get("Content-Type"))
+	b, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name": "sb", "email": null, "address": {"city": ""}}`, string(b))
+
+	req, err = NewRequest(http.MethodPost, "/users", []mo.Option[*string]{mo.Some(&email)})
+	require.NoError(t, err)
+	b, err = io.ReadAll(req.Body)
+	require.NoError(t, err)
+	require.JSONEq(t, `["sb@example.com"]`, string(b))
+
+	req, err = NewRequest(http.MethodPost, "/users", ClientFormDto{Name: mo.Some("sb"), Tags: []string{"a", "b"}})
+	require.NoError(t, err)
+	require.Equal(t, gin.MIMEPOSTForm, req.Header.Get("Content-Type"))
+	b, err = io.ReadAll(req.Body)
+	require.NoError(t, err)
+	require.Equal(t, "name=sb&tags=a&tags=b", string(b))
+}
+
+type ClientNestedInner struct {
+	A mo.Option[string]  `json:"a,omitempty"`
+	B mo.Option[*string] `json:"b,omitempty"`
+}
+
+type ClientNestedDto struct {
+	ID    int64                        `uri:"id" json:"-"`
+	Inner ClientNestedInner            `json:"inner"`
+	Items []ClientNestedInner          `json:"items"`
+	Tags  map[string]mo.Option[string] `json:"tags"`
+}
+
+func TestNewRequestOptionJSON(t *testing.T) {
+	engine := gin.New()
+	engine.PUT("/nested/:id", Handle(func(ctx context.Context, req ClientNestedDto) (ClientNestedDto, error) {
+		return req, nil
+	}))
+	dto := ClientNestedDto{
+		ID:    1,
+		Inner: ClientNestedInner{B: mo.Some[*string](nil)},
+		Items: []ClientNestedInner{{A: mo.Some("a")}, {}},
+		Tags:  map[string]mo.Option[string]{"x": mo.Some("")},
+	}
+	req, err := NewRequest(http.MethodPut, "/nested/:id", dto)
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp ClientNestedDto
+	require.NoError(t, OptionJSON.Unmarshal(w.Body.Bytes(), &resp))
+	// the absent A is still absent, the present nil B is still present
+	require.Equal(t, ClientNestedInner{B: mo.Some[*string](nil)}, resp.Inner)
+	require.Equal(t, dto.Items, resp.Items)
+	require.Equal(t, dto.Tags, resp.Tags)
+}
+
+func TestNewRequestBinding(t *testing.T) {
+	// multipart file from a parsed form
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, err := writer.CreateFormFile("file", "a.txt")
+	require.NoError(t, err)
+	_, err = part.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	form, err := multipart.NewReader(&buf, writer.Boundary()).ReadForm(defaultMemory)
+	require.NoError(t, err)
+
+	engine := gin.New()
+	engine.POST("/files", func(c *gin.Context) {
+		var value ClientFormDto
+		require.NoError(t, c.ShouldBindWith(&value, OptionFormBinding))
+		require.Equal(t, mo.Some("sb"), value.Name)
+		require.Equal(t, mo.None[string](), value.Nick)
+		require.Equal(t, []string{"a", "b"}, value.Tags)
+		file, err := c.FormFile("file")
+		require.NoError(t, err)
+		require.Equal(t, "a.txt", file.Filename)
+		c.Status(http.StatusNoContent)
+	})
+	engine.GET("/users/:id/files/*path", func(c *gin.Context) {
+		var value ClientQueryDto
+		require.NoError(t, c.ShouldBindWith(&value, OptionQueryBinding))
+		require.Equal(t, mo.Some("sb"), value.Name)
+		require.Equal(t, mo.None[string](), value.Nick)
+		require.Equal(t, mo.Some([]int64{1, 2}), value.IDs)
+		require.Equal(t, "/a b/c.txt", c.Param("path"))
+		c.Status(http.StatusNoContent)
+	})
+
+	req, err := NewRequest(http.MethodPost, "/files", ClientFormDto{Name: mo.Some("sb"), Tags: []string{"a", "b"}, File: form.File["file"][0]})
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	req, err = NewRequest(http.MethodGet, "/users/:id/files/*path", ClientQueryDto{ID: 1, Path: "a b/c.txt", Name: mo.Some("sb"), IDs: mo.Some([]int64{1, 2})})
+	require.NoError(t, err)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code)
+}
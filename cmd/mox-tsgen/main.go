@@ -0,0 +1,425 @@
+// mox-tsgen generate TypeScript types from the go types of a package.
+//
+//   - struct: interface, the name of field is from json tag.
+//   - mo.Option[T], mox.Option[T]: `field?: T`.
+//   - patch style mo.Option[*T]: `field?: T | null`, null means set to null, absent means not change.
+//   - pointer: `field: T | null`, and `field?: T | null` with omitempty.
+//   - named scalar, slice and map type: type alias, like `export type Status = string;`.
+//
+// Usage:
+//
+//	mox-tsgen [-o types.ts] [-type User,Status] ./dto
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	moPkgPath  = "github.com/samber/mo"
+	moxPkgPath = "github.com/liruohrh/mox"
+)
+
+func main() {
+	output := flag.String("o", "", "output file, default is stdout")
+	typeNames := flag.String("type", "", "comma separated type names to generate, default is all exported types")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: mox-tsgen [flags] [dir]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	fset := token.NewFileSet()
+	files, err := parseDir(fset, dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "mox-tsgen:", err)
+		os.Exit(1)
+	}
+	var only []string
+	if *typeNames != "" {
+		only = strings.Split(*typeNames, ",")
+	}
+	code := generate(files, only)
+	if *output == "" {
+		fmt.Print(code)
+		return
+	}
+	if err := os.WriteFile(*output, []byte(code), 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "mox-tsgen:", err)
+		os.Exit(1)
+	}
+}
+
+// parseDir parse the go files of dir except test files, sorted by file name.
+func parseDir(fset *token.FileSet, dir string) ([]*ast.File, error) {
+	names, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	var files []*ast.File
+	for _, name := range names {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, name, nil, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no go files in %s", dir)
+	}
+	return files, nil
+}
+
+// typeDecl the type declaration and the imports of its file.
+type typeDecl struct {
+	spec    *ast.TypeSpec
+	doc     *ast.CommentGroup
+	imports map[string]string
+}
+
+type generator struct {
+	pkgPath string
+	decls   map[string]typeDecl
+	buf     strings.Builder
+}
+
+// generate the TypeScript code of the exported types in files, only generate the types in only if not empty.
+func generate(files []*ast.File, only []string) string {
+	g := &generator{decls: map[string]typeDecl{}}
+	var names []string
+	for _, file := range files {
+		imports := fileImports(file)
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				doc := typeSpec.Doc
+				if doc == nil && len(genDecl.Specs) == 1 {
+					doc = genDecl.Doc
+				}
+				g.decls[typeSpec.Name.Name] = typeDecl{spec: typeSpec, doc: doc, imports: imports}
+				names = append(names, typeSpec.Name.Name)
+			}
+		}
+		if file.Name.Name == "mox" {
+			g.pkgPath = moxPkgPath
+		}
+	}
+
+	g.buf.WriteString("// Code generated by mox-tsgen. DO NOT EDIT.\n")
+	for _, name := range names {
+		if !ast.IsExported(name) || (len(only) > 0 && !contains(only, name)) {
+			continue
+		}
+		g.typeDecl(g.decls[name])
+	}
+	return g.buf.String()
+}
+
+func fileImports(file *ast.File) map[string]string {
+	imports := map[string]string{}
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := path[strings.LastIndexByte(path, '/')+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = path
+	}
+	return imports
+}
+
+func (g *generator) typeDecl(decl typeDecl) {
+	spec := decl.spec
+	if _, ok := g.optionElem(spec.Type, decl.imports); ok {
+		// defined option type is only used as field
+		return
+	}
+	switch spec.Type.(type) {
+	case *ast.InterfaceType, *ast.FuncType, *ast.ChanType:
+		return
+	}
+	g.buf.WriteString("\n")
+	writeDoc(&g.buf, decl.doc, "")
+	name := spec.Name.Name + typeParams(spec.TypeParams)
+	structType, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		fmt.Fprintf(&g.buf, "export type %s = %s;\n", name, g.tsType(spec.Type, decl.imports))
+		return
+	}
+	var extends []string
+	for _, field := range structType.Fields.List {
+		if len(field.Names) == 0 && jsonName(field) == "" {
+			if typ := g.tsType(field.Type, decl.imports); typ != "unknown" {
+				extends = append(extends, typ)
+			}
+		}
+	}
+	fmt.Fprintf(&g.buf, "export interface %s ", name)
+	if len(extends) > 0 {
+		fmt.Fprintf(&g.buf, "extends %s ", strings.Join(extends, ", "))
+	}
+	g.buf.WriteString(g.structBody(structType, decl.imports, ""))
+	g.buf.WriteString("\n")
+}
+
+// structBody the body of interface, like `{ name: string; }`.
+func (g *generator) structBody(structType *ast.StructType, imports map[string]string, indent string) string {
+	var buf strings.Builder
+	buf.WriteString("{\n")
+	for _, field := range structType.Fields.List {
+		name, options := jsonTag(field)
+		if name == "-" && len(options) == 0 {
+			continue
+		}
+		var names []string
+		switch {
+		case len(field.Names) == 0 && name == "":
+			// embedded struct is extended
+			continue
+		case name != "":
+			names = []string{name}
+		default:
+			for _, ident := range field.Names {
+				if ident.IsExported() {
+					names = append(names, ident.Name)
+				}
+			}
+		}
+		if len(field.Names) > 0 && !field.Names[0].IsExported() {
+			continue
+		}
+		optional, typ := g.fieldType(field, options, imports)
+		for _, name := range names {
+			writeDoc(&buf, field.Doc, indent+"  ")
+			if optional {
+				name += "?"
+			}
+			fmt.Fprintf(&buf, "%s  %s: %s;\n", indent, propertyName(name), typ)
+		}
+	}
+	buf.WriteString(indent + "}")
+	return buf.String()
+}
+
+// fieldType return whether the field is optional and its type.
+func (g *generator) fieldType(field *ast.Field, options []string, imports map[string]string) (bool, string) {
+	omit := contains(options, "omitempty") || contains(options, "omitzero")
+	if elem, ok := g.optionElem(field.Type, imports); ok {
+		if star, ok := elem.(*ast.StarExpr); ok {
+			return true, g.tsType(star.X, imports) + " | null"
+		}
+		return true, g.tsType(elem, imports)
+	}
+	if star, ok := field.Type.(*ast.StarExpr); ok {
+		return omit, g.tsType(star.X, imports) + " | null"
+	}
+	return omit, g.tsType(field.Type, imports)
+}
+
+// optionElem return the element type if expr is mo.Option[T], mox.Option[T] or defined type of them.
+func (g *generator) optionElem(expr ast.Expr, imports map[string]string) (ast.Expr, bool) {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return g.optionElem(e.X, imports)
+	case *ast.IndexExpr:
+		switch x := e.X.(type) {
+		case *ast.SelectorExpr:
+			pkg, ok := x.X.(*ast.Ident)
+			if !ok || x.Sel.Name != "Option" {
+				return nil, false
+			}
+			path := imports[pkg.Name]
+			if path == moPkgPath || path == moxPkgPath {
+				return e.Index, true
+			}
+		case *ast.Ident:
+			if x.Name == "Option" && g.pkgPath == moxPkgPath {
+				return e.Index, true
+			}
+		}
+	case *ast.Ident:
+		if decl, ok := g.decls[e.Name]; ok && decl.spec.TypeParams == nil {
+			return g.optionElem(decl.spec.Type, decl.imports)
+		}
+	case *ast.StructType:
+		// struct only embedding an option
+		if e.Fields.NumFields() == 1 && len(e.Fields.List[0].Names) == 0 {
+			return g.optionElem(e.Fields.List[0].Type, imports)
+		}
+	}
+	return nil, false
+}
+
+// tsType the TypeScript type of expr.
+func (g *generator) tsType(expr ast.Expr, imports map[string]string) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		switch e.Name {
+		case "bool":
+			return "boolean"
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+			"float32", "float64", "byte", "rune":
+			return "number"
+		case "string":
+			return "string"
+		case "any":
+			return "unknown"
+		case "error":
+			return "string"
+		}
+		if decl, ok := g.decls[e.Name]; ok {
+			if elem, ok := g.optionElem(e, decl.imports); ok {
+				return g.tsType(elem, decl.imports)
+			}
+		}
+		return e.Name
+	case *ast.StarExpr:
+		return g.tsType(e.X, imports)
+	case *ast.ParenExpr:
+		return g.tsType(e.X, imports)
+	case *ast.ArrayType:
+		if ident, ok := e.Elt.(*ast.Ident); ok && (ident.Name == "byte" || ident.Name == "uint8") {
+			// base64
+			return "string"
+		}
+		elem := g.elemType(e.Elt, imports)
+		if strings.ContainsAny(elem, "|& ") {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]"
+	case *ast.MapType:
+		key := g.tsType(e.Key, imports)
+		if key != "number" {
+			key = "string"
+		}
+		return "Record<" + key + ", " + g.elemType(e.Value, imports) + ">"
+	case *ast.SelectorExpr:
+		pkg, _ := e.X.(*ast.Ident)
+		if pkg == nil {
+			return "unknown"
+		}
+		switch imports[pkg.Name] + "." + e.Sel.Name {
+		case "time.Time":
+			return "string"
+		case "time.Duration":
+			return "number"
+		case "encoding/json.RawMessage":
+			return "unknown"
+		case "database/sql.NullString", "database/sql.NullTime":
+			return "string | null"
+		case "database/sql.NullInt64", "database/sql.NullInt32", "database/sql.NullInt16",
+			"database/sql.NullByte", "database/sql.NullFloat64":
+			return "number | null"
+		case "database/sql.NullBool":
+			return "boolean | null"
+		}
+		return "unknown"
+	case *ast.IndexExpr:
+		if elem, ok := g.optionElem(e, imports); ok {
+			return g.elemType(&ast.StarExpr{X: elem}, imports)
+		}
+		return g.tsType(e.X, imports) + "<" + g.tsType(e.Index, imports) + ">"
+	case *ast.IndexListExpr:
+		args := make([]string, len(e.Indices))
+		for i, index := range e.Indices {
+			args[i] = g.tsType(index, imports)
+		}
+		return g.tsType(e.X, imports) + "<" + strings.Join(args, ", ") + ">"
+	case *ast.StructType:
+		return g.structBody(e, imports, "")
+	default:
+		return "unknown"
+	}
+}
+
+// elemType the type of slice or map element, option and pointer can be null.
+func (g *generator) elemType(expr ast.Expr, imports map[string]string) string {
+	if elem, ok := g.optionElem(expr, imports); ok {
+		return g.elemType(&ast.StarExpr{X: elem}, imports)
+	}
+	if star, ok := expr.(*ast.StarExpr); ok {
+		return g.tsType(star.X, imports) + " | null"
+	}
+	return g.tsType(expr, imports)
+}
+
+func typeParams(fields *ast.FieldList) string {
+	if fields == nil {
+		return ""
+	}
+	var names []string
+	for _, field := range fields.List {
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+	}
+	return "<" + strings.Join(names, ", ") + ">"
+}
+
+func jsonTag(field *ast.Field) (string, []string) {
+	if field.Tag == nil {
+		return "", nil
+	}
+	tag, _ := strconv.Unquote(field.Tag.Value)
+	parts := strings.Split(reflect.StructTag(tag).Get("json"), ",")
+	return parts[0], parts[1:]
+}
+
+func jsonName(field *ast.Field) string {
+	name, _ := jsonTag(field)
+	return name
+}
+
+// propertyName quote the name if it is not an identifier.
+func propertyName(name string) string {
+	base := strings.TrimSuffix(name, "?")
+	for i, c := range base {
+		if c != '_' && c != '$' && !('a' <= c && c <= 'z') && !('A' <= c && c <= 'Z') && (i == 0 || !('0' <= c && c <= '9')) {
+			return strconv.Quote(base) + name[len(base):]
+		}
+	}
+	return name
+}
+
+func writeDoc(buf *strings.Builder, doc *ast.CommentGroup, indent string) {
+	text := strings.TrimSpace(doc.Text())
+	if text == "" {
+		return
+	}
+	buf.WriteString(indent + "/**\n")
+	for _, line := range strings.Split(text, "\n") {
+		buf.WriteString(strings.TrimRight(indent+" * "+line, " ") + "\n")
+	}
+	buf.WriteString(indent + " */\n")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const tsgenSource = `package dto
+
+import (
+	"time"
+
+	"github.com/liruohrh/mox"
+	"github.com/samber/mo"
+)
+
+// Status the status of user.
+type Status string
+
+type MaybeName mo.Option[string]
+
+type Base struct {
+	ID        int64     ` + "`json:\"id\"`" + `
+	CreatedAt time.Time ` + "`json:\"created_at\"`" + `
+}
+
+// User the user.
+type User struct {
+	Base
+	// Name the name of user.
+	Name     mo.Option[string]   ` + "`json:\"name,omitempty\"`" + `
+	Nick     mo.Option[*string]  ` + "`json:\"nick,omitempty\"`" + `
+	Alias    mox.Option[string]  ` + "`json:\"alias,omitzero\"`" + `
+	Maybe    MaybeName           ` + "`json:\"maybe\"`" + `
+	Status   Status              ` + "`json:\"status\"`" + `
+	Parent   *User               ` + "`json:\"parent,omitempty\"`" + `
+	Tags     []mo.Option[string] ` + "`json:\"tags\"`" + `
+	Extra    map[string]any      ` + "`json:\"extra-info\"`" + `
+	Avatar   []byte
+	Secret   string ` + "`json:\"-\"`" + `
+	internal string
+}
+
+type Page[T any] struct {
+	Items []T   ` + "`json:\"items\"`" + `
+	Total int64 ` + "`json:\"total\"`" + `
+}
+
+type UserPage Page[User]
+
+type Store interface {
+	Get() User
+}
+
+type hidden struct{}
+`
+
+func TestGenerate(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "dto.go", tsgenSource, parser.ParseComments)
+	require.NoError(t, err)
+	require.Equal(t, `// Code generated by mox-tsgen. DO NOT EDIT.
+
+/**
+ * Status the status of user.
+ */
+export type Status = string;
+
+export interface Base {
+  id: number;
+  created_at: string;
+}
+
+/**
+ * User the user.
+ */
+export interface User extends Base {
+  /**
+   * Name the name of user.
+   */
+  name?: string;
+  nick?: string | null;
+  alias?: string;
+  maybe?: string;
+  status: Status;
+  parent?: User | null;
+  tags: (string | null)[];
+  "extra-info": Record<string, unknown>;
+  Avatar: string;
+}
+
+export interface Page<T> {
+  items: T[];
+  total: number;
+}
+
+export type UserPage = Page<User>;
+`, generate([]*ast.File{file}, nil))
+
+	require.Equal(t, `// Code generated by mox-tsgen. DO NOT EDIT.
+
+/**
+ * Status the status of user.
+ */
+export type Status = string;
+`, generate([]*ast.File{file}, []string{"Status"}))
+}
@@ -0,0 +1,254 @@
+package mox
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/samber/mo"
+)
+
+// FromNull convert sql.Null to mo.Option.
+func FromNull[T any](v sql.Null[T]) mo.Option[T] {
+	return mo.TupleToOption(v.V, v.Valid)
+}
+
+// ToNull convert mo.Option to sql.Null.
+func ToNull[T any](o mo.Option[T]) sql.Null[T] {
+	v, ok := o.Get()
+	return sql.Null[T]{V: v, Valid: ok}
+}
+
+// FromNullString convert sql.NullString to mo.Option.
+func FromNullString(v sql.NullString) mo.Option[string] {
+	return mo.TupleToOption(v.String, v.Valid)
+}
+
+// ToNullString convert mo.Option to sql.NullString.
+func ToNullString(o mo.Option[string]) sql.NullString {
+	v, ok := o.Get()
+	return sql.NullString{String: v, Valid: ok}
+}
+
+// FromNullInt64 convert sql.NullInt64 to mo.Option.
+func FromNullInt64(v sql.NullInt64) mo.Option[int64] {
+	return mo.TupleToOption(v.Int64, v.Valid)
+}
+
+// ToNullInt64 convert mo.Option to sql.NullInt64.
+func ToNullInt64(o mo.Option[int64]) sql.NullInt64 {
+	v, ok := o.Get()
+	return sql.NullInt64{Int64: v, Valid: ok}
+}
+
+// FromNullInt32 convert sql.NullInt32 to mo.Option.
+func FromNullInt32(v sql.NullInt32) mo.Option[int32] {
+	return mo.TupleToOption(v.Int32, v.Valid)
+}
+
+// ToNullInt32 convert mo.Option to sql.NullInt32.
+func ToNullInt32(o mo.Option[int32]) sql.NullInt32 {
+	v, ok := o.Get()
+	return sql.NullInt32{Int32: v, Valid: ok}
+}
+
+// FromNullInt16 convert sql.NullInt16 to mo.Option.
+func FromNullInt16(v sql.NullInt16) mo.Option[int16] {
+	return mo.TupleToOption(v.Int16, v.Valid)
+}
+
+// ToNullInt16 convert mo.Option to sql.NullInt16.
+func ToNullInt16(o mo.Option[int16]) sql.NullInt16 {
+	v, ok := o.Get()
+	return sql.NullInt16{Int16: v, Valid: ok}
+}
+
+// FromNullByte convert sql.NullByte to mo.Option.
+func FromNullByte(v sql.NullByte) mo.Option[byte] {
+	return mo.TupleToOption(v.Byte, v.Valid)
+}
+
+// ToNullByte convert mo.Option to sql.NullByte.
+func ToNullByte(o mo.Option[byte]) sql.NullByte {
+	v, ok := o.Get()
+	return sql.NullByte{Byte: v, Valid: ok}
+}
+
+// FromNullFloat64 convert sql.NullFloat64 to mo.Option.
+func FromNullFloat64(v sql.NullFloat64) mo.Option[float64] {
+	return mo.TupleToOption(v.Float64, v.Valid)
+}
+
+// ToNullFloat64 convert mo.Option to sql.NullFloat64.
+func ToNullFloat64(o mo.Option[float64]) sql.NullFloat64 {
+	v, ok := o.Get()
+	return sql.NullFloat64{Float64: v, Valid: ok}
+}
+
+// FromNullBool convert sql.NullBool to mo.Option.
+func FromNullBool(v sql.NullBool) mo.Option[bool] {
+	return mo.TupleToOption(v.Bool, v.Valid)
+}
+
+// ToNullBool convert mo.Option to sql.NullBool.
+func ToNullBool(o mo.Option[bool]) sql.NullBool {
+	v, ok := o.Get()
+	return sql.NullBool{Bool: v, Valid: ok}
+}
+
+// FromNullTime convert sql.NullTime to mo.Option.
+func FromNullTime(v sql.NullTime) mo.Option[time.Time] {
+	return mo.TupleToOption(v.Time, v.Valid)
+}
+
+// ToNullTime convert mo.Option to sql.NullTime.
+func ToNullTime(o mo.Option[time.Time]) sql.NullTime {
+	v, ok := o.Get()
+	return sql.NullTime{Time: v, Valid: ok}
+}
+
+var sqlScannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// ScanStruct scan the current row of rows into the struct dst by column name, the field name is from db tag.
+// the column without field is discarded, NULL is scanned as None for option.
+func ScanStruct(rows *sql.Rows, dst any) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: %T", ErrOnlyStruct, dst)
+	}
+	v = v.Elem()
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	fields := tagFields(v.Type(), "db")
+	targets := make([]any, len(columns))
+	for i, column := range columns {
+		field, ok := lookupTagField(fields, column)
+		if !ok {
+			targets[i] = new(any)
+			continue
+		}
+		targets[i] = scanTarget(v.FieldByIndex(field.Index))
+	}
+	return rows.Scan(targets...)
+}
+
+// ScanAll scan all rows into T by ScanStruct, rows will be closed.
+func ScanAll[T any](rows *sql.Rows) ([]T, error) {
+	defer rows.Close()
+	var values []T
+	for rows.Next() {
+		var value T
+		if err := ScanStruct(rows, &value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+// scanTarget return the target of rows.Scan for the settable field.
+func scanTarget(field reflect.Value) any {
+	if reflect.PointerTo(field.Type()).Implements(sqlScannerType) {
+		return field.Addr().Interface()
+	}
+	if info, ok := OptionOf(field.Type()); ok {
+		return &optionScanner{info: info, option: field}
+	}
+	return field.Addr().Interface()
+}
+
+// optionScanner scan the option which is not a sql.Scanner, like defined type of mo.Option.
+type optionScanner struct {
+	info   OptionInfo
+	option reflect.Value
+}
+
+func (s *optionScanner) Scan(src any) error {
+	if src == nil {
+		s.info.unset(s.option)
+		return nil
+	}
+	value := reflect.New(s.info.Elem)
+	if scanner, ok := value.Interface().(sql.Scanner); ok {
+		if err := scanner.Scan(src); err != nil {
+			return err
+		}
+		s.info.set(s.option, value.Elem())
+		return nil
+	}
+	// the driver value is int64, float64, bool, []byte, string or time.Time
+	if b, ok := src.([]byte); ok {
+		// the []byte is only valid until next scan
+		src = bytes.Clone(b)
+	}
+	converted := reflect.ValueOf(src)
+	switch {
+	case converted.Type().AssignableTo(s.info.Elem):
+	case converted.Type().ConvertibleTo(s.info.Elem) && sqlConvertible(converted.Kind(), s.info.Elem.Kind()):
+		if !sqlNumberFits(converted, s.info.Elem) {
+			return fmt.Errorf("%w: can not scan %T(%v) into %s, value out of range", ErrNotSupportOptionValueKind, src, src, s.info.Elem)
+		}
+		converted = converted.Convert(s.info.Elem)
+	default:
+		return fmt.Errorf("%w: can not scan %T into %s", ErrNotSupportOptionValueKind, src, s.info.Elem)
+	}
+	s.info.set(s.option, converted)
+	return nil
+}
+
+// sqlNumberFits report whether the number v can be converted to t without overflow or truncation, like database/sql.
+// the values which are not numbers always fit.
+func sqlNumberFits(v reflect.Value, t reflect.Type) bool {
+	target := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return !target.OverflowInt(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return v.Uint() <= math.MaxInt64 && !target.OverflowInt(int64(v.Uint()))
+		case reflect.Float32, reflect.Float64:
+			f := v.Float()
+			return f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 && !target.OverflowInt(int64(f))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return v.Int() >= 0 && !target.OverflowUint(uint64(v.Int()))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return !target.OverflowUint(v.Uint())
+		case reflect.Float32, reflect.Float64:
+			f := v.Float()
+			return f == math.Trunc(f) && f >= 0 && f < math.MaxUint64 && !target.OverflowUint(uint64(f))
+		}
+	case reflect.Float32, reflect.Float64:
+		if v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64 {
+			return !target.OverflowFloat(v.Float())
+		}
+	}
+	return true
+}
+
+// sqlConvertible only allow the conversion between numbers, string and []byte.
+func sqlConvertible(from reflect.Kind, to reflect.Kind) bool {
+	kindClass := func(kind reflect.Kind) int {
+		switch kind {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return 1
+		case reflect.String, reflect.Slice:
+			return 2
+		case reflect.Bool:
+			return 3
+		default:
+			return 0
+		}
+	}
+	return kindClass(from) != 0 && kindClass(from) == kindClass(to)
+}
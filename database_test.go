@@ -0,0 +1,172 @@
+package mox
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+)
+
+// stubDriver return the fixed columns and rows for any query.
+type stubDriver struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (d *stubDriver) Open(string) (driver.Conn, error) {
+	return &stubConn{driver: d}, nil
+}
+
+type stubConn struct {
+	driver *stubDriver
+}
+
+func (c *stubConn) Prepare(string) (driver.Stmt, error) {
+	return &stubStmt{driver: c.driver}, nil
+}
+
+func (c *stubConn) Close() error {
+	return nil
+}
+
+func (c *stubConn) Begin() (driver.Tx, error) {
+	return nil, driver.ErrSkip
+}
+
+type stubStmt struct {
+	driver *stubDriver
+}
+
+func (s *stubStmt) Close() error {
+	return nil
+}
+
+func (s *stubStmt) NumInput() int {
+	return -1
+}
+
+func (s *stubStmt) Exec([]driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+
+func (s *stubStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &stubRows{driver: s.driver}, nil
+}
+
+type stubRows struct {
+	driver *stubDriver
+	index  int
+}
+
+func (r *stubRows) Columns() []string {
+	return r.driver.columns
+}
+
+func (r *stubRows) Close() error {
+	return nil
+}
+
+func (r *stubRows) Next(dest []driver.Value) error {
+	if r.index >= len(r.driver.rows) {
+		return io.EOF
+	}
+	copy(dest, r.driver.rows[r.index])
+	r.index++
+	return nil
+}
+
+func TestNullConvert(t *testing.T) {
+	require.Equal(t, mo.Some(1), FromNull(sql.Null[int]{V: 1, Valid: true}))
+	require.Equal(t, mo.None[int](), FromNull(sql.Null[int]{V: 1}))
+	require.Equal(t, sql.Null[int]{V: 1, Valid: true}, ToNull(mo.Some(1)))
+	require.Equal(t, sql.Null[int]{}, ToNull(mo.None[int]()))
+
+	require.Equal(t, mo.Some("s"), FromNullString(sql.NullString{String: "s", Valid: true}))
+	require.Equal(t, sql.NullString{}, ToNullString(mo.None[string]()))
+	require.Equal(t, mo.Some(int64(1)), FromNullInt64(sql.NullInt64{Int64: 1, Valid: true}))
+	require.Equal(t, sql.NullInt64{Int64: 1, Valid: true}, ToNullInt64(mo.Some(int64(1))))
+	require.Equal(t, mo.None[int32](), FromNullInt32(sql.NullInt32{}))
+	require.Equal(t, sql.NullInt32{Int32: 1, Valid: true}, ToNullInt32(mo.Some(int32(1))))
+	require.Equal(t, mo.Some(int16(1)), FromNullInt16(sql.NullInt16{Int16: 1, Valid: true}))
+	require.Equal(t, sql.NullInt16{}, ToNullInt16(mo.None[int16]()))
+	require.Equal(t, mo.Some(byte(1)), FromNullByte(sql.NullByte{Byte: 1, Valid: true}))
+	require.Equal(t, sql.NullByte{Byte: 1, Valid: true}, ToNullByte(mo.Some(byte(1))))
+	require.Equal(t, mo.Some(1.5), FromNullFloat64(sql.NullFloat64{Float64: 1.5, Valid: true}))
+	require.Equal(t, sql.NullFloat64{}, ToNullFloat64(mo.None[float64]()))
+	require.Equal(t, mo.Some(false), FromNullBool(sql.NullBool{Valid: true}))
+	require.Equal(t, sql.NullBool{Bool: true, Valid: true}, ToNullBool(mo.Some(true)))
+	now := time.Now()
+	require.Equal(t, mo.Some(now), FromNullTime(sql.NullTime{Time: now, Valid: true}))
+	require.Equal(t, sql.NullTime{Time: now, Valid: true}, ToNullTime(mo.Some(now)))
+}
+
+func TestScanStruct(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	sql.Register("mox_stub", &stubDriver{
+		columns: []string{"id", "name", "nick_name", "age", "created_at", "unknown"},
+		rows: [][]driver.Value{
+			{int64(1), "sb", []byte("nick"), int64(18), createdAt, "x"},
+			{int64(2), "sb2", nil, nil, nil, nil},
+		},
+	})
+	db, err := sql.Open("mox_stub", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	type User struct {
+		ID        int64                `db:"id"`
+		Name      Option[string]       `db:"name"`
+		Nick      MaybeName            `db:"nick_name"`
+		Age       mo.Option[int]       `db:"age"`
+		CreatedAt mo.Option[time.Time] `db:"created_at"`
+	}
+	rows, err := db.Query("SELECT * FROM user")
+	require.NoError(t, err)
+	users, err := ScanAll[User](rows)
+	require.NoError(t, err)
+	require.Equal(t, []User{
+		{ID: 1, Name: Some("sb"), Nick: MaybeName(mo.Some("nick")), Age: mo.Some(18), CreatedAt: mo.Some(createdAt)},
+		{ID: 2, Name: Some("sb2"), Nick: MaybeName(mo.None[string]()), Age: mo.None[int](), CreatedAt: mo.None[time.Time]()},
+	}, users)
+
+	rows, err = db.Query("SELECT * FROM user")
+	require.NoError(t, err)
+	defer rows.Close()
+	require.True(t, rows.Next())
+	var user User
+	require.NoError(t, ScanStruct(rows, &user))
+	require.Equal(t, users[0], user)
+	require.ErrorIs(t, ScanStruct(rows, user), ErrOnlyStruct)
+}
+
+func TestOptionScannerRange(t *testing.T) {
+	type MaybeInt8 mo.Option[int8]
+	type MaybeUint mo.Option[uint]
+	type MaybeFloat32 mo.Option[float32]
+	var i8 MaybeInt8
+	scanner := scanTarget(reflect.ValueOf(&i8).Elem()).(sql.Scanner)
+	require.NoError(t, scanner.Scan(int64(-128)))
+	require.Equal(t, MaybeInt8(mo.Some[int8](-128)), i8)
+	require.NoError(t, scanner.Scan(float64(100)))
+	require.Equal(t, MaybeInt8(mo.Some[int8](100)), i8)
+	require.ErrorIs(t, scanner.Scan(int64(300)), ErrNotSupportOptionValueKind)
+	require.ErrorIs(t, scanner.Scan(float64(1.5)), ErrNotSupportOptionValueKind)
+
+	var u MaybeUint
+	scanner = scanTarget(reflect.ValueOf(&u).Elem()).(sql.Scanner)
+	require.NoError(t, scanner.Scan(int64(math.MaxInt64)))
+	require.Equal(t, MaybeUint(mo.Some[uint](math.MaxInt64)), u)
+	require.ErrorIs(t, scanner.Scan(int64(-1)), ErrNotSupportOptionValueKind)
+
+	var f MaybeFloat32
+	scanner = scanTarget(reflect.ValueOf(&f).Elem()).(sql.Scanner)
+	require.NoError(t, scanner.Scan(int64(3)))
+	require.Equal(t, MaybeFloat32(mo.Some[float32](3)), f)
+	require.ErrorIs(t, scanner.Scan(math.MaxFloat64), ErrNotSupportOptionValueKind)
+}
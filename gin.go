@@ -1,13 +1,14 @@
 package mox
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/samber/lo"
-	"github.com/samber/mo"
 	"net/http"
+	"net/textproto"
 	"reflect"
 	"strconv"
 	"strings"
@@ -25,23 +26,48 @@ var (
 
 const defaultMemory = 32 << 20
 
+// BindFieldError the error of setting the values of field in binding.
+type BindFieldError struct {
+	// Field the name of form, uri or header.
+	Field string
+	Value []string
+	Err   error
+}
+
+func (e *BindFieldError) Error() string {
+	return fmt.Sprintf("bind %s: %s", e.Field, e.Err)
+}
+
+func (e *BindFieldError) Unwrap() error {
+	return e.Err
+}
+
 type optionFormBinding struct{}
 
 func (optionFormBinding) Name() string {
 	return "OptionForm"
 }
 
-func (optionFormBinding) Bind(req *http.Request, obj any) error {
-	if err := req.ParseForm(); err != nil {
+func (b optionFormBinding) Bind(req *http.Request, obj any) error {
+	if err := b.bind(req, obj); err != nil {
 		return err
 	}
-	if err := req.ParseMultipartForm(defaultMemory); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+	return validate(req.Context(), obj)
+}
+
+func (optionFormBinding) bind(req *http.Request, obj any) error {
+	if err := req.ParseForm(); err != nil {
 		return err
 	}
-	if err := mapForm(obj, req.Form); err != nil {
+	if err := req.ParseMultipartForm(defaultMemory); err != nil && !errors.Is(err, http.ErrNotMultipart) {
 		return err
 	}
-	return validate(obj)
+	return mapForm(obj, req.Form)
+}
+
+// Scenario the binding validate in the scenario, see ValidateScenario.
+func (b optionFormBinding) Scenario(scenario string) binding.Binding {
+	return &scenarioBinding{binding: b, scenario: scenario}
 }
 
 func ShouldBindGinUri(c *gin.Context, obj any) error {
@@ -52,7 +78,7 @@ func ShouldBindGinUri(c *gin.Context, obj any) error {
 	if err := mapForm(obj, values); err != nil {
 		return err
 	}
-	return validate(obj)
+	return validate(c, obj)
 }
 
 type optionQueryBinding struct {
@@ -63,21 +89,61 @@ func (t *optionQueryBinding) Name() string {
 }
 
 func (t *optionQueryBinding) Bind(req *http.Request, obj any) error {
-	values := req.URL.Query()
-	if err := mapForm(obj, values); err != nil {
+	if err := t.bind(req, obj); err != nil {
 		return err
 	}
-	return validate(obj)
+	return validate(req.Context(), obj)
 }
 
-func validate(obj any) error {
-	if binding.Validator == nil {
-		return nil
+func (t *optionQueryBinding) bind(req *http.Request, obj any) error {
+	return mapForm(obj, req.URL.Query())
+}
+
+// Scenario the binding validate in the scenario, see ValidateScenario.
+func (t *optionQueryBinding) Scenario(scenario string) binding.Binding {
+	return &scenarioBinding{binding: t, scenario: scenario}
+}
+
+type scenarioBinding struct {
+	binding interface {
+		Name() string
+		bind(req *http.Request, obj any) error
+	}
+	scenario string
+}
+
+func (b *scenarioBinding) Name() string {
+	return b.binding.Name()
+}
+
+func (b *scenarioBinding) Bind(req *http.Request, obj any) error {
+	if err := b.binding.bind(req, obj); err != nil {
+		return err
+	}
+	return ValidateScenarioCtx(req.Context(), obj, b.scenario)
+}
+
+// validate obj by binding.Validator with the request context, see ValidateScenarioCtx.
+func validate(ctx context.Context, obj any) error {
+	return ValidateScenarioCtx(ctx, obj, "")
+}
+
+// ContextMiddleware put the *gin.Context into the request context, so the context-aware validators in the bindings
+// which only get *http.Request can read the values set by c.Set, see GPContextValue.
+func ContextMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), gin.ContextKey, c))
+		c.Next()
 	}
-	return binding.Validator.ValidateStruct(obj)
 }
 
 func mapForm(ptr any, form map[string][]string) error {
+	return mapFormByTag(ptr, form, "form", false)
+}
+
+// mapFormByTag map the form by the name of tag, the name of header tag is canonicalized.
+// the field without tag is mapped by field name, unless tagged, which only map the fields with tag.
+func mapFormByTag(ptr any, form map[string][]string, tagKey string, tagged bool) error {
 	// Check if ptr is a map
 	ptrValue := reflect.ValueOf(ptr)
 	if ptrValue.Kind() == reflect.Ptr {
@@ -90,8 +156,8 @@ func mapForm(ptr any, form map[string][]string) error {
 	ptrType := ptrValue.Type()
 	for i := range ptrValue.NumField() {
 		field := ptrType.Field(i)
-		tag := field.Tag.Get("form")
-		if tag == "-" {
+		tag, ok := field.Tag.Lookup(tagKey)
+		if tag == "-" || (tagged && !ok) {
 			continue
 		}
 		tags := lo.Filter(strings.Split(tag, ","), func(item string, index int) bool {
@@ -105,19 +171,22 @@ func mapForm(ptr any, form map[string][]string) error {
 		if name == "" {
 			name = field.Name
 		}
+		if tagKey == "header" {
+			name = textproto.CanonicalMIMEHeaderKey(name)
+		}
 		vs := form[name]
 		if len(vs) == 0 {
 			continue
 		}
 		fieldValue := ptrValue.Field(i)
 
-		if IsOption(field.Type) {
-			if err := setOptionValue(vs, fieldValue, field); err != nil {
-				return err
+		if info, ok := OptionOf(field.Type); ok {
+			if err := setOptionValue(vs, fieldValue, field, info); err != nil {
+				return &BindFieldError{Field: name, Value: vs, Err: err}
 			}
 		} else {
 			if err := setValue(vs, fieldValue, field); err != nil {
-				return err
+				return &BindFieldError{Field: name, Value: vs, Err: err}
 			}
 		}
 	}
@@ -140,15 +209,15 @@ func setValue(vs []string, value reflect.Value, field reflect.StructField) error
 	}
 	return nil
 }
-func setOptionValue(vs []string, value reflect.Value, field reflect.StructField) error {
-	optionValue := value.FieldByName("value")
+func setOptionValue(vs []string, value reflect.Value, field reflect.StructField, info OptionInfo) error {
+	optionValue := reflect.New(info.Elem).Elem()
 	switch optionValue.Kind() {
 	case reflect.Slice:
-		if err := setOptionSlice(vs, value, field, optionValue); err != nil {
+		if err := setOptionSlice(vs, value, field, info, optionValue); err != nil {
 			return err
 		}
 	default:
-		if err := setWithProperOptionType(vs[0], value, field, optionValue); err != nil {
+		if err := setWithProperOptionType(vs[0], value, field, info, optionValue); err != nil {
 			return err
 		}
 	}
@@ -273,20 +342,20 @@ func setWithProperType(val string, value reflect.Value, field reflect.StructFiel
 	return nil
 }
 
-func setOptionSlice(vs []string, value reflect.Value, field reflect.StructField, optionValue reflect.Value) error {
+func setOptionSlice(vs []string, value reflect.Value, field reflect.StructField, info OptionInfo, optionValue reflect.Value) error {
 	if optionValue.Type().Elem().Kind() == reflect.String {
-		value.Set(reflect.ValueOf(mo.Some[[]string](vs)))
+		info.set(value, reflect.ValueOf(vs))
 		return nil
 	}
 	switch optionValue.Type().Elem().Kind() {
 	default:
 		return fmt.Errorf("%w: %s is %s", ErrNotSupportOptionValueKind, field.Name, optionValue.Kind().String())
 	case reflect.Bool:
-		return _setOptionSlice[bool](vs, value, field, optionValue, func(str string) (bool, error) {
+		return _setOptionSlice[bool](vs, value, field, info, optionValue, func(str string) (bool, error) {
 			return str == "" || str == "true", nil
 		})
 	case reflect.Int:
-		return _setOptionSlice[int](vs, value, field, optionValue, func(str string) (int, error) {
+		return _setOptionSlice[int](vs, value, field, info, optionValue, func(str string) (int, error) {
 			v, err := strconv.ParseInt(str, 10, 0)
 			if err != nil {
 				return 0, err
@@ -294,7 +363,7 @@ func setOptionSlice(vs []string, value reflect.Value, field reflect.StructField,
 			return int(v), nil
 		})
 	case reflect.Int8:
-		return _setOptionSlice[int8](vs, value, field, optionValue, func(str string) (int8, error) {
+		return _setOptionSlice[int8](vs, value, field, info, optionValue, func(str string) (int8, error) {
 			v, err := strconv.ParseInt(str, 10, 8)
 			if err != nil {
 				return 0, err
@@ -302,7 +371,7 @@ func setOptionSlice(vs []string, value reflect.Value, field reflect.StructField,
 			return int8(v), nil
 		})
 	case reflect.Int16:
-		return _setOptionSlice[int16](vs, value, field, optionValue, func(str string) (int16, error) {
+		return _setOptionSlice[int16](vs, value, field, info, optionValue, func(str string) (int16, error) {
 			v, err := strconv.ParseInt(str, 10, 16)
 			if err != nil {
 				return 0, err
@@ -310,7 +379,7 @@ func setOptionSlice(vs []string, value reflect.Value, field reflect.StructField,
 			return int16(v), nil
 		})
 	case reflect.Int32:
-		return _setOptionSlice[int32](vs, value, field, optionValue, func(str string) (int32, error) {
+		return _setOptionSlice[int32](vs, value, field, info, optionValue, func(str string) (int32, error) {
 			v, err := strconv.ParseInt(str, 10, 32)
 			if err != nil {
 				return 0, err
@@ -320,7 +389,7 @@ func setOptionSlice(vs []string, value reflect.Value, field reflect.StructField,
 	case reflect.Int64:
 		switch optionValue.Type().Elem() {
 		case reflect.TypeOf(time.Nanosecond):
-			return _setOptionSlice[time.Duration](vs, value, field, optionValue, func(str string) (time.Duration, error) {
+			return _setOptionSlice[time.Duration](vs, value, field, info, optionValue, func(str string) (time.Duration, error) {
 				v, err := time.ParseDuration(str)
 				if err != nil {
 					return 0, err
@@ -328,7 +397,7 @@ func setOptionSlice(vs []string, value reflect.Value, field reflect.StructField,
 				return v, nil
 			})
 		default:
-			return _setOptionSlice[int64](vs, value, field, optionValue, func(str string) (int64, error) {
+			return _setOptionSlice[int64](vs, value, field, info, optionValue, func(str string) (int64, error) {
 				v, err := strconv.ParseInt(str, 10, 64)
 				if err != nil {
 					return 0, err
@@ -337,7 +406,7 @@ func setOptionSlice(vs []string, value reflect.Value, field reflect.StructField,
 			})
 		}
 	case reflect.Uint:
-		return _setOptionSlice[uint](vs, value, field, optionValue, func(str string) (uint, error) {
+		return _setOptionSlice[uint](vs, value, field, info, optionValue, func(str string) (uint, error) {
 			v, err := strconv.ParseUint(str, 10, 0)
 			if err != nil {
 				return 0, err
@@ -345,7 +414,7 @@ func setOptionSlice(vs []string, value reflect.Value, field reflect.StructField,
 			return uint(v), nil
 		})
 	case reflect.Uint8:
-		return _setOptionSlice[uint8](vs, value, field, optionValue, func(str string) (uint8, error) {
+		return _setOptionSlice[uint8](vs, value, field, info, optionValue, func(str string) (uint8, error) {
 			v, err := strconv.ParseUint(str, 10, 8)
 			if err != nil {
 				return 0, err
@@ -353,7 +422,7 @@ func setOptionSlice(vs []string, value reflect.Value, field reflect.StructField,
 			return uint8(v), nil
 		})
 	case reflect.Uint16:
-		return _setOptionSlice[uint16](vs, value, field, optionValue, func(str string) (uint16, error) {
+		return _setOptionSlice[uint16](vs, value, field, info, optionValue, func(str string) (uint16, error) {
 			v, err := strconv.ParseUint(str, 10, 16)
 			if err != nil {
 				return 0, err
@@ -361,7 +430,7 @@ func setOptionSlice(vs []string, value reflect.Value, field reflect.StructField,
 			return uint16(v), nil
 		})
 	case reflect.Uint32:
-		return _setOptionSlice[uint32](vs, value, field, optionValue, func(str string) (uint32, error) {
+		return _setOptionSlice[uint32](vs, value, field, info, optionValue, func(str string) (uint32, error) {
 			v, err := strconv.ParseUint(str, 10, 32)
 			if err != nil {
 				return 0, err
@@ -369,7 +438,7 @@ func setOptionSlice(vs []string, value reflect.Value, field reflect.StructField,
 			return uint32(v), nil
 		})
 	case reflect.Uint64:
-		return _setOptionSlice[uint64](vs, value, field, optionValue, func(str string) (uint64, error) {
+		return _setOptionSlice[uint64](vs, value, field, info, optionValue, func(str string) (uint64, error) {
 			v, err := strconv.ParseUint(str, 10, 64)
 			if err != nil {
 				return 0, err
@@ -377,7 +446,7 @@ func setOptionSlice(vs []string, value reflect.Value, field reflect.StructField,
 			return v, nil
 		})
 	case reflect.Float32:
-		return _setOptionSlice[float32](vs, value, field, optionValue, func(str string) (float32, error) {
+		return _setOptionSlice[float32](vs, value, field, info, optionValue, func(str string) (float32, error) {
 			v, err := strconv.ParseFloat(str, 32)
 			if err != nil {
 				return 0, err
@@ -385,7 +454,7 @@ func setOptionSlice(vs []string, value reflect.Value, field reflect.StructField,
 			return float32(v), nil
 		})
 	case reflect.Float64:
-		return _setOptionSlice[float64](vs, value, field, optionValue, func(str string) (float64, error) {
+		return _setOptionSlice[float64](vs, value, field, info, optionValue, func(str string) (float64, error) {
 			v, err := strconv.ParseFloat(str, 64)
 			if err != nil {
 				return 0, err
@@ -395,21 +464,21 @@ func setOptionSlice(vs []string, value reflect.Value, field reflect.StructField,
 	}
 }
 
-func _setOptionSlice[T int | int8 | int16 | int32 | ~int64 | uint | uint8 | uint16 | uint32 | uint64 | float32 | float64 | string | bool](vs []string, value reflect.Value, field reflect.StructField, optionValue reflect.Value, convert func(v string) (T, error)) error {
+func _setOptionSlice[T int | int8 | int16 | int32 | ~int64 | uint | uint8 | uint16 | uint32 | uint64 | float32 | float64 | string | bool](vs []string, value reflect.Value, field reflect.StructField, info OptionInfo, optionValue reflect.Value, convert func(v string) (T, error)) error {
 	values := make([]T, 0, len(vs))
 	for _, v := range vs {
 		tv, err := convert(v)
 		if err != nil {
-			return fmt.Errorf("convert %s to %s: %w", field.Name, optionValue.Elem().Type(), err)
+			return fmt.Errorf("convert %s to %s: %w", field.Name, optionValue.Type().Elem(), err)
 		}
 		values = append(values, tv)
 	}
-	value.Set(reflect.ValueOf(mo.Some[[]T](values)))
+	info.set(value, reflect.ValueOf(values))
 	return nil
 }
 
 // for can not set no export field
-func setWithProperOptionType(val string, option reflect.Value, field reflect.StructField, optionValue reflect.Value) error {
+func setWithProperOptionType(val string, option reflect.Value, field reflect.StructField, info OptionInfo, optionValue reflect.Value) error {
 	if val == "" && optionValue.Kind() != reflect.String && optionValue.Kind() != reflect.Bool {
 		return fmt.Errorf("can use empty string to %s: %s", field.Name, optionValue.Kind())
 	}
@@ -417,90 +486,90 @@ func setWithProperOptionType(val string, option reflect.Value, field reflect.Str
 	default:
 		return fmt.Errorf("%w: %s is %s", ErrNotSupportOptionValueKind, field.Name, optionValue.Kind().String())
 	case reflect.String:
-		option.Set(reflect.ValueOf(mo.Some[string](val)))
+		info.set(option, reflect.ValueOf(val))
 	case reflect.Bool:
-		option.Set(reflect.ValueOf(mo.Some[bool](val == "" || val == "true")))
+		info.set(option, reflect.ValueOf(val == "" || val == "true"))
 	case reflect.Int:
 		v, err := strconv.ParseInt(val, 10, 0)
 		if err != nil {
 			return err
 		}
-		option.Set(reflect.ValueOf(mo.Some[int](int(v))))
+		info.set(option, reflect.ValueOf(int(v)))
 	case reflect.Int8:
 		v, err := strconv.ParseInt(val, 10, 8)
 		if err != nil {
 			return err
 		}
-		option.Set(reflect.ValueOf(mo.Some[int8](int8(v))))
+		info.set(option, reflect.ValueOf(int8(v)))
 	case reflect.Int16:
 		v, err := strconv.ParseInt(val, 10, 16)
 		if err != nil {
 			return err
 		}
-		option.Set(reflect.ValueOf(mo.Some[int16](int16(v))))
+		info.set(option, reflect.ValueOf(int16(v)))
 	case reflect.Int32:
 		v, err := strconv.ParseInt(val, 10, 32)
 		if err != nil {
 			return err
 		}
-		option.Set(reflect.ValueOf(mo.Some[int32](int32(v))))
+		info.set(option, reflect.ValueOf(int32(v)))
 	case reflect.Int64:
-		switch option.Interface().(type) {
+		switch optionValue.Interface().(type) {
 		case time.Duration:
 			v, err := time.ParseDuration(val)
 			if err != nil {
 				return err
 			}
-			option.Set(reflect.ValueOf(mo.Some(v)))
+			info.set(option, reflect.ValueOf(v))
 		default:
 			v, err := strconv.ParseInt(val, 10, 64)
 			if err != nil {
 				return err
 			}
-			option.Set(reflect.ValueOf(mo.Some[int64](v)))
+			info.set(option, reflect.ValueOf(v))
 		}
 	case reflect.Uint:
 		v, err := strconv.ParseUint(val, 10, 0)
 		if err != nil {
 			return err
 		}
-		option.Set(reflect.ValueOf(mo.Some[uint](uint(v))))
+		info.set(option, reflect.ValueOf(uint(v)))
 	case reflect.Uint8:
 		v, err := strconv.ParseUint(val, 10, 8)
 		if err != nil {
 			return err
 		}
-		option.Set(reflect.ValueOf(mo.Some[uint8](uint8(v))))
+		info.set(option, reflect.ValueOf(uint8(v)))
 	case reflect.Uint16:
 		v, err := strconv.ParseUint(val, 10, 16)
 		if err != nil {
 			return err
 		}
-		option.Set(reflect.ValueOf(mo.Some[uint16](uint16(v))))
+		info.set(option, reflect.ValueOf(uint16(v)))
 	case reflect.Uint32:
 		v, err := strconv.ParseUint(val, 10, 32)
 		if err != nil {
 			return err
 		}
-		option.Set(reflect.ValueOf(mo.Some[uint32](uint32(v))))
+		info.set(option, reflect.ValueOf(uint32(v)))
 	case reflect.Uint64:
 		v, err := strconv.ParseUint(val, 10, 64)
 		if err != nil {
 			return err
 		}
-		option.Set(reflect.ValueOf(mo.Some[uint64](v)))
+		info.set(option, reflect.ValueOf(v))
 	case reflect.Float32:
 		v, err := strconv.ParseFloat(val, 32)
 		if err != nil {
 			return err
 		}
-		option.Set(reflect.ValueOf(mo.Some[float32](float32(v))))
+		info.set(option, reflect.ValueOf(float32(v)))
 	case reflect.Float64:
 		v, err := strconv.ParseFloat(val, 64)
 		if err != nil {
 			return err
 		}
-		option.Set(reflect.ValueOf(mo.Some[float64](v)))
+		info.set(option, reflect.ValueOf(v))
 	}
 	return nil
 }
@@ -7,9 +7,6 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
-	"net/url"
-	"reflect"
-	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -19,114 +16,6 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-// generateQueryString uses reflection to generate URL query string from struct
-func generateQueryString(obj interface{}) string {
-	v := reflect.ValueOf(obj)
-	t := reflect.TypeOf(obj)
-
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
-		t = t.Elem()
-	}
-
-	var params []string
-
-	for i := 0; i < v.NumField(); i++ {
-		field := t.Field(i)
-		fieldValue := v.Field(i)
-
-		formTag := field.Tag.Get("form")
-		if formTag == "" {
-			continue
-		}
-
-		// Extract the form name (first part before comma)
-		formName := strings.Split(formTag, ",")[0]
-
-		switch fieldValue.Kind() {
-		case reflect.Slice, reflect.Array:
-			for j := 0; j < fieldValue.Len(); j++ {
-				elem := fieldValue.Index(j)
-				value := formatValue(elem)
-				if value != "" {
-					params = append(params, fmt.Sprintf("%s=%s", formName, url.QueryEscape(value)))
-				}
-			}
-		case reflect.Ptr:
-			if !fieldValue.IsNil() {
-				value := formatValue(fieldValue.Elem())
-				if value != "" {
-					params = append(params, fmt.Sprintf("%s=%s", formName, url.QueryEscape(value)))
-				}
-			}
-		default:
-			// Handle Option types
-			if field.Type.PkgPath() == "github.com/samber/mo" && strings.HasPrefix(field.Type.Name(), "Option[") {
-				// Check if Option has value
-				isPresent := fieldValue.FieldByName("isPresent")
-				if isPresent.IsValid() && isPresent.Bool() {
-					optionValue := fieldValue.FieldByName("value")
-					if optionValue.IsValid() {
-						// Handle different Option value types
-						switch optionValue.Kind() {
-						case reflect.Slice, reflect.Array:
-							// Handle Option[[]T] and Option[[2]T]
-							for j := 0; j < optionValue.Len(); j++ {
-								elem := optionValue.Index(j)
-								value := formatValue(elem)
-								if value != "" {
-									params = append(params, fmt.Sprintf("%s=%s", formName, url.QueryEscape(value)))
-								}
-							}
-						case reflect.Ptr:
-							// Handle Option[*T]
-							if !optionValue.IsNil() {
-								value := formatValue(optionValue.Elem())
-								if value != "" {
-									params = append(params, fmt.Sprintf("%s=%s", formName, url.QueryEscape(value)))
-								}
-							}
-						default:
-							// Handle Option[T] for basic types
-							value := formatValue(optionValue)
-							if value != "" {
-								params = append(params, fmt.Sprintf("%s=%s", formName, url.QueryEscape(value)))
-							}
-						}
-					}
-				}
-			} else {
-				value := formatValue(fieldValue)
-				if value != "" {
-					params = append(params, fmt.Sprintf("%s=%s", formName, url.QueryEscape(value)))
-				}
-			}
-		}
-	}
-
-	return strings.Join(params, "&")
-}
-
-// formatValue converts reflect.Value to string
-func formatValue(v reflect.Value) string {
-	switch v.Kind() {
-	case reflect.String:
-		return v.String()
-	case reflect.Bool:
-		return strconv.FormatBool(v.Bool())
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return strconv.FormatInt(v.Int(), 10)
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return strconv.FormatUint(v.Uint(), 10)
-	case reflect.Float32:
-		return strconv.FormatFloat(v.Float(), 'f', -1, 32)
-	case reflect.Float64:
-		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
-	default:
-		return ""
-	}
-}
-
 func TestGin(t *testing.T) {
 	var err error
 
@@ -247,8 +136,9 @@ func TestGin(t *testing.T) {
 		SliceFloat64Option: mo.Some([]float64{rand.Float64() * 100, rand.Float64() * 100}),
 	}
 
-	// Generate query string using reflection
-	queryString := generateQueryString(v)
+	query, err := EncodeForm(v)
+	require.NoError(t, err)
+	queryString := query.Encode()
 	fmt.Printf("Generated query string: %s\n", queryString)
 
 	engine := gin.Default()
@@ -275,8 +165,6 @@ func TestGin(t *testing.T) {
 
 	var buf bytes.Buffer
 	multipartWriter := multipart.NewWriter(&buf)
-	query, err := url.ParseQuery(queryString)
-	require.NoError(t, err)
 	for name, vs := range query {
 		for _, v := range vs {
 			require.NoError(t, multipartWriter.WriteField(name, v))
@@ -1,35 +1,156 @@
 package mox
 
 import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/samber/mo"
 	"reflect"
+	"strings"
+	"sync"
+	"time"
 )
 
 // RegisterGPVUnwrapOptionTypeFunc why unwrap? because use value for other validate func
 func RegisterGPVUnwrapOptionTypeFunc(validate *validator.Validate) {
-	validate.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
-		if v, ok := field.Interface().(mo.Option[string]); ok {
-			if v.IsPresent() {
-				return v.MustGet()
-			} else {
-				return nil
-			}
+	RegisterGPVOption[string](validate)
+}
+
+// RegisterGPVOption unwrap mo.Option[T] and mox.Option[T].
+func RegisterGPVOption[T any](validate *validator.Validate) {
+	validate.RegisterCustomTypeFunc(gpvUnwrapOption, mo.Option[T]{}, Option[T]{})
+}
+
+// RegisterGPVOptionBuiltin unwrap the option of builtin scalar types, their slices, time.Time and time.Duration.
+func RegisterGPVOptionBuiltin(validate *validator.Validate) {
+	RegisterGPVOption[bool](validate)
+	RegisterGPVOption[int](validate)
+	RegisterGPVOption[int8](validate)
+	RegisterGPVOption[int16](validate)
+	RegisterGPVOption[int32](validate)
+	RegisterGPVOption[int64](validate)
+	RegisterGPVOption[uint](validate)
+	RegisterGPVOption[uint8](validate)
+	RegisterGPVOption[uint16](validate)
+	RegisterGPVOption[uint32](validate)
+	RegisterGPVOption[uint64](validate)
+	RegisterGPVOption[float32](validate)
+	RegisterGPVOption[float64](validate)
+	RegisterGPVOption[string](validate)
+	RegisterGPVOption[[]bool](validate)
+	RegisterGPVOption[[]int](validate)
+	RegisterGPVOption[[]int8](validate)
+	RegisterGPVOption[[]int16](validate)
+	RegisterGPVOption[[]int32](validate)
+	RegisterGPVOption[[]int64](validate)
+	RegisterGPVOption[[]uint](validate)
+	RegisterGPVOption[[]uint8](validate)
+	RegisterGPVOption[[]uint16](validate)
+	RegisterGPVOption[[]uint32](validate)
+	RegisterGPVOption[[]uint64](validate)
+	RegisterGPVOption[[]float32](validate)
+	RegisterGPVOption[[]float64](validate)
+	RegisterGPVOption[[]string](validate)
+	RegisterGPVOption[time.Time](validate)
+	RegisterGPVOption[time.Duration](validate)
+	RegisterGPVOption[[]time.Time](validate)
+	RegisterGPVOption[[]time.Duration](validate)
+}
+
+// RegisterGPVOptionOf unwrap every option type used by the dtos, the fields of struct, elements of slice, array and map,
+// pointers and option values are walked recursively.
+// the unwrapped slice, map and struct work with dive, keys and nested struct validation,
+// like `validate:"omitnone,dive,email"` for mo.Option[[]string], the namespace is the full path, like Dto.Address.City, Dto.Emails[1].
+func RegisterGPVOptionOf(validate *validator.Validate, dtos ...any) {
+	visited := map[reflect.Type]bool{}
+	var options []any
+	for _, dto := range dtos {
+		var t reflect.Type
+		switch v := dto.(type) {
+		case reflect.Type:
+			t = v
+		default:
+			t = reflect.TypeOf(dto)
+		}
+		collectOptionTypes(t, visited, &options)
+	}
+	if len(options) > 0 {
+		validate.RegisterCustomTypeFunc(gpvUnwrapOption, options...)
+	}
+}
+
+func collectOptionTypes(t reflect.Type, visited map[reflect.Type]bool, options *[]any) {
+	if t == nil || visited[t] {
+		return
+	}
+	visited[t] = true
+	if info, ok := OptionOf(t); ok && !info.Pointer {
+		*options = append(*options, reflect.Zero(t).Interface())
+		collectOptionTypes(info.Elem, visited, options)
+		return
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		collectOptionTypes(t.Elem(), visited, options)
+	case reflect.Map:
+		collectOptionTypes(t.Key(), visited, options)
+		collectOptionTypes(t.Elem(), visited, options)
+	case reflect.Struct:
+		for i := range t.NumField() {
+			collectOptionTypes(t.Field(i).Type, visited, options)
 		}
+	}
+}
+func gpvUnwrapOption(field reflect.Value) interface{} {
+	info, ok := OptionOf(field.Type())
+	if !ok {
 		return nil
-	}, mo.Option[string]{})
+	}
+	if value, ok := info.get(field); ok {
+		return value.Interface()
+	}
+	// typed nil pointer, so omitnone can skip it
+	return reflect.Zero(reflect.PointerTo(info.Elem)).Interface()
+}
+
+// RegisterGPValidatorOmitNone add tag omitnone: skip the remaining rules if option is None, else apply them to the value,
+// like omitnil, Some("") is validated as "".
+// the option type must be unwrapped, see RegisterGPVOption, Some(nil) of mo.Option[*T] is also skipped.
+// it panics if the option type is not unwrapped, instead of applying the rules to the option struct.
+func RegisterGPValidatorOmitNone(validate *validator.Validate) {
+	// the tag is not registered yet, so the error is impossible
+	_ = validate.RegisterValidation("omitnone_unwrapped", gpValidatorOmitNoneUnwrapped)
+	validate.RegisterAlias("omitnone", "omitnil,omitnone_unwrapped")
+}
+func gpValidatorOmitNoneUnwrapped(fl validator.FieldLevel) bool {
+	if _, ok := OptionOf(fl.Field().Type()); ok {
+		panic(fmt.Sprintf("omitnone: option type %s of %s is not unwrapped, register it by RegisterGPVOption or RegisterGPVOptionOf", fl.Field().Type(), fl.StructFieldName()))
+	}
+	return true
 }
 
-// RegisterGPValidatorPresent require option.IsPresent=true
+// RegisterGPValidatorPresent require option.IsPresent=true, Some(nil) of mo.Option[*T] is present even if unwrapped.
 func RegisterGPValidatorPresent(validate *validator.Validate) error {
-	return validate.RegisterValidation("present", gpValidatorPresent)
+	return validate.RegisterValidation("present", gpValidatorPresent, true)
 }
 func gpValidatorPresent(fl validator.FieldLevel) bool {
 	field := fl.Field()
-	if !IsOption(field.Type()) {
-		return true
+	// the unwrapped Some(nil) is nil, so use the raw field, unless field is the element of dive.
+	if raw, ok := lookupStructField(fl.Parent(), fl.StructFieldName()); ok {
+		if info, ok := OptionOf(raw.Type()); ok && field.IsValid() &&
+			(field.Type() == raw.Type() || field.Type() == info.Elem || field.Type() == reflect.PointerTo(info.Elem)) {
+			return info.isPresent(raw)
+		}
+	}
+	if !field.IsValid() {
+		return false
+	}
+	if info, ok := OptionOf(field.Type()); ok {
+		return info.isPresent(field)
 	}
-	return field.MethodByName("IsPresent").Call(nil)[0].Interface().(bool)
+	// the nil is absent as the validator without callValidationEvenIfNull
+	return field.Kind() != reflect.Ptr || !field.IsNil()
 }
 
 // RegisterGPValidatorNotNil notnil: mandatory, allows zero value (except nil)
@@ -42,6 +163,14 @@ func RegisterGPValidatorNotNil(validate *validator.Validate) error {
 }
 func gpValidatorNotNil(fl validator.FieldLevel) bool {
 	field := fl.Field()
+	if info, ok := OptionOf(field.Type()); ok {
+		// the option is not unwrapped, same as unwrapped: absent and Some(nil) are nil
+		value, ok := info.get(field)
+		if !ok {
+			return false
+		}
+		field = value
+	}
 
 	switch field.Kind() {
 	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map, reflect.Chan, reflect.Func:
@@ -50,3 +179,242 @@ func gpValidatorNotNil(fl validator.FieldLevel) bool {
 		return true // No validation for non-reference types
 	}
 }
+
+// RegisterGPContextValidator add the context-aware tag, fn is called with the value of key in the validating context,
+// the field is invalid if the value is absent or not T, see GPContextValue.
+// like the allowed values loaded from config, or the tenant set by gin middleware with c.Set(key, tenant).
+func RegisterGPContextValidator[T any](validate *validator.Validate, tag string, key any, fn func(fl validator.FieldLevel, value T) bool, callValidationEvenIfNull ...bool) error {
+	return validate.RegisterValidationCtx(tag, GPContextValidator(key, fn), callValidationEvenIfNull...)
+}
+
+// GPContextValidator the validator.FuncCtx call fn with the value of key in ctx, false if the value is absent or not T.
+func GPContextValidator[T any](key any, fn func(fl validator.FieldLevel, value T) bool) validator.FuncCtx {
+	return func(ctx context.Context, fl validator.FieldLevel) bool {
+		value, ok := GPContextValue[T](ctx, key)
+		return ok && fn(fl, value)
+	}
+}
+
+// GPContextValue get the value of key from ctx, the string key is also looked up in the keys of *gin.Context,
+// which is ctx itself in BindRequest and ShouldBindGinUri, or put into the request context by ContextMiddleware.
+func GPContextValue[T any](ctx context.Context, key any) (T, bool) {
+	if ctx == nil {
+		var zero T
+		return zero, false
+	}
+	if c, ok := ctx.Value(gin.ContextKey).(*gin.Context); ok && c != nil {
+		if name, ok := key.(string); ok {
+			if value, exists := c.Get(name); exists {
+				value, ok := value.(T)
+				return value, ok
+			}
+		}
+	}
+	value, ok := ctx.Value(key).(T)
+	return value, ok
+}
+
+// gpTagNameValidators the validators registered GPTagNameFunc, for the field name of struct level error.
+var gpTagNameValidators sync.Map
+
+// RegisterGPTagNameFunc use GPTagNameFunc as the field name of validation error.
+func RegisterGPTagNameFunc(validate *validator.Validate) {
+	validate.RegisterTagNameFunc(GPTagNameFunc)
+	gpTagNameValidators.Store(validate, struct{}{})
+}
+
+// GPTagNameFunc the field name is from json tag, then form, uri, header tag, then field name.
+// "-" is ignored instead of skipping the field, because the field is still bound from other sources.
+func GPTagNameFunc(field reflect.StructField) string {
+	name, _, _ := fieldTagName(field, []string{"json", "form", "uri", "header"})
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// RegisterGPValidatorPresence add the cross-field tags by option presence, the param is the names of struct fields.
+//   - present_with=A B: present if any of A, B is present.
+//   - present_without=A B: present if any of A, B is absent.
+//   - present_if=A a B b: present if A is present and equal to a, and B ..., the last value can be omitted to only check presence.
+//   - absent_with=A B: absent if any of A, B is present.
+//   - absent_without=A B: absent if any of A, B is absent.
+//   - excluded_if_present=A a: absent if A is present and equal to a, same param as present_if.
+//
+// present: option is present, pointer, interface, slice and map are not nil, other values are always present.
+func RegisterGPValidatorPresence(validate *validator.Validate) error {
+	for tag, fn := range map[string]validator.Func{
+		"present_with":        gpValidatorPresentWith,
+		"present_without":     gpValidatorPresentWithout,
+		"present_if":          gpValidatorPresentIf,
+		"absent_with":         gpValidatorAbsentWith,
+		"absent_without":      gpValidatorAbsentWithout,
+		"excluded_if_present": gpValidatorExcludedIfPresent,
+	} {
+		if err := validate.RegisterValidation(tag, fn, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func gpValidatorPresentWith(fl validator.FieldLevel) bool {
+	return !anyPresence(fl, true) || isFieldPresent(fl)
+}
+
+func gpValidatorPresentWithout(fl validator.FieldLevel) bool {
+	return !anyPresence(fl, false) || isFieldPresent(fl)
+}
+
+func gpValidatorPresentIf(fl validator.FieldLevel) bool {
+	return !matchPresentIf(fl) || isFieldPresent(fl)
+}
+
+func gpValidatorAbsentWith(fl validator.FieldLevel) bool {
+	return !anyPresence(fl, true) || !isFieldPresent(fl)
+}
+
+func gpValidatorAbsentWithout(fl validator.FieldLevel) bool {
+	return !anyPresence(fl, false) || !isFieldPresent(fl)
+}
+
+func gpValidatorExcludedIfPresent(fl validator.FieldLevel) bool {
+	return !matchPresentIf(fl) || !isFieldPresent(fl)
+}
+
+// isFieldPresent report whether the current field is present, the raw field is used because the option may be unwrapped.
+func isFieldPresent(fl validator.FieldLevel) bool {
+	if field, ok := lookupStructField(fl.Parent(), fl.StructFieldName()); ok {
+		return isPresentValue(field)
+	}
+	return isPresentValue(fl.Field())
+}
+
+// anyPresence report whether any field of param has the presence.
+func anyPresence(fl validator.FieldLevel, present bool) bool {
+	for _, name := range strings.Fields(fl.Param()) {
+		field, ok := lookupStructField(fl.Parent(), name)
+		if ok && isPresentValue(field) == present {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPresentIf report whether all fields of param are present and equal to the values.
+func matchPresentIf(fl validator.FieldLevel) bool {
+	params := strings.Fields(fl.Param())
+	for i := 0; i < len(params); i += 2 {
+		field, ok := lookupStructField(fl.Parent(), params[i])
+		if !ok {
+			return false
+		}
+		value, ok := presentValue(field)
+		if !ok {
+			return false
+		}
+		if i+1 < len(params) && fmt.Sprint(value.Interface()) != params[i+1] {
+			return false
+		}
+	}
+	return true
+}
+
+// lookupStructField get the field of struct by dotted go field names, like Address.City.
+func lookupStructField(v reflect.Value, name string) (reflect.Value, bool) {
+	for _, part := range strings.Split(name, ".") {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		if info, ok := OptionOf(v.Type()); ok {
+			value, ok := info.get(v)
+			if !ok {
+				return reflect.Value{}, false
+			}
+			v = value
+		}
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+		v = v.FieldByName(part)
+		if !v.IsValid() {
+			return reflect.Value{}, false
+		}
+	}
+	return v, true
+}
+
+func isPresentValue(v reflect.Value) bool {
+	_, ok := presentValue(v)
+	return ok
+}
+
+// presentValue unwrap option and pointer, return false if absent or nil.
+func presentValue(v reflect.Value) (reflect.Value, bool) {
+	if !v.IsValid() {
+		return reflect.Value{}, false
+	}
+	if info, ok := OptionOf(v.Type()); ok {
+		value, ok := info.get(v)
+		if !ok {
+			return reflect.Value{}, false
+		}
+		v = value
+	}
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.IsNil() {
+		return reflect.Value{}, false
+	}
+	return v, true
+}
+
+// GPExactlyOnePresent the struct level validation, exactly one of fields is present, else report exactly_one_present on the first field.
+//
+//	validate.RegisterStructValidation(mox.GPExactlyOnePresent("ID", "Email"), FindUserDto{})
+func GPExactlyOnePresent(fields ...string) validator.StructLevelFunc {
+	return gpPresentCount("exactly_one_present", fields, func(count int) bool { return count == 1 })
+}
+
+// GPAtLeastOnePresent the struct level validation, at least one of fields is present, else report at_least_one_present on the first field.
+func GPAtLeastOnePresent(fields ...string) validator.StructLevelFunc {
+	return gpPresentCount("at_least_one_present", fields, func(count int) bool { return count > 0 })
+}
+
+// GPStructLevels combine the struct level validations, because only one can be registered for a type.
+func GPStructLevels(fns ...validator.StructLevelFunc) validator.StructLevelFunc {
+	return func(sl validator.StructLevel) {
+		for _, fn := range fns {
+			fn(sl)
+		}
+	}
+}
+
+func gpPresentCount(tag string, fields []string, valid func(count int) bool) validator.StructLevelFunc {
+	return func(sl validator.StructLevel) {
+		count := 0
+		for _, name := range fields {
+			if field, ok := lookupStructField(sl.Current(), name); ok && isPresentValue(field) {
+				count++
+			}
+		}
+		if valid(count) || len(fields) == 0 {
+			return
+		}
+		field, _ := lookupStructField(sl.Current(), fields[0])
+		fieldName := fields[0]
+		if _, ok := gpTagNameValidators.Load(sl.Validator()); ok {
+			if structField, ok := sl.Current().Type().FieldByName(fields[0]); ok {
+				fieldName = GPTagNameFunc(structField)
+			}
+		}
+		sl.ReportError(field, fieldName, fields[0], tag, strings.Join(fields, " "))
+	}
+}
@@ -1,9 +1,15 @@
 package mox
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"github.com/samber/lo"
 	"github.com/samber/mo"
 	"github.com/stretchr/testify/require"
 )
@@ -182,3 +188,342 @@ func TestGoPlaygroundValidator(t *testing.T) {
 		V: &emptyStr,
 	}), "failed on the 'min' tag")
 }
+
+type GPVOptionItem struct {
+	Score mo.Option[float64] `validate:"max=100"`
+}
+
+type GPVOptionDto struct {
+	Age   mo.Option[int]              `validate:"min=1"`
+	Tags  mo.Option[[]string]         `validate:"max=2"`
+	At    mo.Option[time.Time]        `validate:"required"`
+	Names *mo.Option[MaybeName]       `validate:"omitnil"`
+	Items []GPVOptionItem             `validate:"dive"`
+	Map   map[string]Option[[2]int64] `validate:"dive,required"`
+}
+
+func TestGoPlaygroundOption(t *testing.T) {
+	invalid := GPVOptionDto{Age: mo.Some(0), Tags: mo.Some([]string{"a", "b", "c"}), Items: []GPVOptionItem{{Score: mo.Some(101.0)}}, Map: map[string]Option[[2]int64]{"a": None[[2]int64]()}}
+
+	// not unwrapped, min panics on the wrapper struct
+	type AgeDto struct {
+		Age mo.Option[int] `validate:"min=1"`
+	}
+	require.Panics(t, func() { _ = validator.New().Struct(&AgeDto{Age: mo.Some(0)}) })
+
+	validate := validator.New()
+	RegisterGPVOption[int](validate)
+	err := validate.Struct(&AgeDto{Age: mo.Some(0)})
+	require.ErrorContains(t, err, "'AgeDto.Age' Error:Field validation for 'Age' failed on the 'min' tag")
+
+	validate = validator.New()
+	RegisterGPVOptionBuiltin(validate)
+	err = validate.Struct(&invalid)
+	require.Len(t, err.(validator.ValidationErrors), 4)
+	require.ErrorContains(t, err, "'Age' failed on the 'min' tag")
+	require.ErrorContains(t, err, "'Tags' failed on the 'max' tag")
+	require.ErrorContains(t, err, "'At' failed on the 'required' tag")
+	require.ErrorContains(t, err, "'Score' failed on the 'max' tag")
+
+	validate = validator.New()
+	RegisterGPVOptionOf(validate, GPVOptionDto{})
+	err = validate.Struct(&invalid)
+	require.Len(t, err.(validator.ValidationErrors), 5)
+	require.ErrorContains(t, err, "'Map[a]' failed on the 'required' tag")
+	require.NoError(t, validate.Struct(&GPVOptionDto{
+		Age:   mo.Some(1),
+		Tags:  mo.Some([]string{"a"}),
+		At:    mo.Some(time.Now()),
+		Items: []GPVOptionItem{{Score: mo.Some(100.0)}},
+		Map:   map[string]Option[[2]int64]{"a": Some([2]int64{1, 2})},
+	}))
+}
+
+func TestGoPlaygroundOmitNone(t *testing.T) {
+	validate := validator.New()
+	RegisterGPVOptionBuiltin(validate)
+	RegisterGPValidatorOmitNone(validate)
+	require.NoError(t, RegisterGPValidatorPresent(validate))
+
+	type OmitNoneDto struct {
+		Name mo.Option[string]   `validate:"omitnone,min=2"`
+		Age  mo.Option[int]      `validate:"omitnone,gte=1"`
+		Tags mo.Option[[]string] `validate:"omitnone,max=1,dive,required"`
+		Nick mo.Option[string]   `validate:"min=2"`
+	}
+	require.NoError(t, validate.Struct(&OmitNoneDto{Nick: mo.Some("sb")}))
+	require.NoError(t, validate.Struct(&OmitNoneDto{Name: mo.Some("sb"), Age: mo.Some(1), Tags: mo.Some([]string{"a"}), Nick: mo.Some("sb")}))
+
+	err := validate.Struct(&OmitNoneDto{Name: mo.Some(""), Age: mo.Some(0), Tags: mo.Some([]string{""})})
+	require.Len(t, err.(validator.ValidationErrors), 4)
+	require.ErrorContains(t, err, "'Name' failed on the 'min' tag")
+	require.ErrorContains(t, err, "'Age' failed on the 'gte' tag")
+	require.ErrorContains(t, err, "'Tags[0]' failed on the 'required' tag")
+	// without omitnone, None fails
+	require.ErrorContains(t, err, "'Nick' failed on the 'min' tag")
+
+	require.ErrorContains(t, validate.Struct(&struct {
+		V mo.Option[string] `validate:"present,omitnone,min=2"`
+	}{}), "'V' failed on the 'present' tag")
+
+	// the defined option type is not unwrapped by the builtin registration
+	type MaybeScore mo.Option[int]
+	type UnregisteredDto struct {
+		Score MaybeScore `validate:"omitnone,min=1"`
+	}
+	require.PanicsWithValue(t, "omitnone: option type mox.MaybeScore of Score is not unwrapped, register it by RegisterGPVOption or RegisterGPVOptionOf", func() {
+		_ = validate.Struct(UnregisteredDto{Score: MaybeScore(mo.Some(0))})
+	})
+	RegisterGPVOptionOf(validate, UnregisteredDto{})
+	require.NoError(t, validate.Struct(UnregisteredDto{}))
+	require.ErrorContains(t, validate.Struct(UnregisteredDto{Score: MaybeScore(mo.Some(0))}), "'Score' failed on the 'min' tag")
+}
+
+type PresenceDto struct {
+	Start  mo.Option[time.Time] `validate:"absent_without=End"`
+	End    mo.Option[time.Time] `validate:"present_with=Start"`
+	Kind   mo.Option[string]
+	Email  mo.Option[string] `validate:"present_if=Kind email"`
+	Phone  *string           `validate:"excluded_if_present=Kind email"`
+	Name   mo.Option[string] `validate:"present_without=Email Phone"`
+	Nick   mo.Option[string] `validate:"absent_with=Name"`
+	ID     mo.Option[int64]
+	Search mo.Option[string]
+}
+
+func TestGoPlaygroundPresence(t *testing.T) {
+	for _, unwrap := range []bool{false, true} {
+		validate := validator.New()
+		if unwrap {
+			RegisterGPVOptionOf(validate, PresenceDto{})
+		}
+		require.NoError(t, RegisterGPValidatorPresence(validate))
+		validate.RegisterStructValidation(GPStructLevels(
+			GPExactlyOnePresent("ID", "Email", "Phone"),
+			GPAtLeastOnePresent("Name", "Search"),
+		), PresenceDto{})
+
+		phone := "123"
+		require.NoError(t, validate.Struct(&PresenceDto{Phone: &phone, Name: mo.Some("sb")}))
+		require.NoError(t, validate.Struct(&PresenceDto{
+			Start: mo.Some(time.Now()),
+			End:   mo.Some(time.Now()),
+			Kind:  mo.Some("email"),
+			Email: mo.Some(""),
+			Name:  mo.Some("sb"),
+		}))
+
+		err := validate.Struct(&PresenceDto{
+			Start: mo.Some(time.Now()),
+			Kind:  mo.Some("email"),
+			Phone: &phone,
+			Name:  mo.Some("sb"),
+			Nick:  mo.Some("sb"),
+		})
+		require.Error(t, err, "unwrap=%v", unwrap)
+		tags := map[string]string{}
+		for _, fe := range err.(validator.ValidationErrors) {
+			tags[fe.StructField()] = fe.Tag()
+		}
+		require.Equal(t, map[string]string{
+			"Start": "absent_without",
+			"End":   "present_with",
+			"Email": "present_if",
+			"Phone": "excluded_if_present",
+			"Nick":  "absent_with",
+		}, tags, "unwrap=%v", unwrap)
+
+		err = validate.Struct(&PresenceDto{ID: mo.Some[int64](1), Email: mo.Some("a"), Name: mo.Some("sb")})
+		require.ErrorContains(t, err, "'ID' failed on the 'exactly_one_present' tag")
+		err = validate.Struct(&PresenceDto{ID: mo.Some[int64](1)})
+		require.ErrorContains(t, err, "'Name' failed on the 'at_least_one_present' tag")
+		require.ErrorContains(t, err, "'Name' failed on the 'present_without' tag")
+	}
+}
+
+type DiveAddress struct {
+	City string            `validate:"required"`
+	Zip  mo.Option[string] `validate:"omitnone,len=6"`
+}
+
+type DiveDto struct {
+	Emails    mo.Option[[]string]               `validate:"omitnone,dive,email"`
+	Scores    mo.Option[map[string]int]         `validate:"omitnone,dive,keys,min=2,endkeys,gt=0"`
+	Address   mo.Option[DiveAddress]            `validate:"omitnone"`
+	Addresses mo.Option[[]DiveAddress]          `validate:"omitnone,min=1,dive"`
+	Pointer   Option[*DiveAddress]              `validate:"omitnone"`
+	Nested    mo.Option[[]mo.Option[[]string]]  `validate:"omitnone,dive,omitnone,dive,min=2"`
+	Map       map[string]mo.Option[DiveAddress] `validate:"dive"`
+}
+
+func TestGoPlaygroundDive(t *testing.T) {
+	validate := validator.New()
+	RegisterGPValidatorOmitNone(validate)
+	RegisterGPVOptionOf(validate, DiveDto{})
+
+	require.NoError(t, validate.Struct(&DiveDto{}))
+	require.NoError(t, validate.Struct(&DiveDto{
+		Emails:    mo.Some([]string{"a@b.c"}),
+		Scores:    mo.Some(map[string]int{"ab": 1}),
+		Address:   mo.Some(DiveAddress{City: "sz"}),
+		Addresses: mo.Some([]DiveAddress{{City: "sz", Zip: mo.Some("518000")}}),
+		Pointer:   Some(&DiveAddress{City: "sz"}),
+		Nested:    mo.Some([]mo.Option[[]string]{mo.None[[]string](), mo.Some([]string{"ab"})}),
+		Map:       map[string]mo.Option[DiveAddress]{"a": mo.None[DiveAddress]()},
+	}))
+
+	err := validate.Struct(&DiveDto{
+		Emails:    mo.Some([]string{"a@b.c", "a"}),
+		Scores:    mo.Some(map[string]int{"a": 1, "bc": 0}),
+		Address:   mo.Some(DiveAddress{Zip: mo.Some("")}),
+		Addresses: mo.Some([]DiveAddress{{City: "sz"}, {}}),
+		Pointer:   Some(&DiveAddress{}),
+		Nested:    mo.Some([]mo.Option[[]string]{mo.Some([]string{"a"})}),
+		Map:       map[string]mo.Option[DiveAddress]{"a": mo.Some(DiveAddress{})},
+	})
+	require.Error(t, err)
+	var namespaces []string
+	for _, fe := range err.(validator.ValidationErrors) {
+		namespaces = append(namespaces, fe.Namespace()+" "+fe.Tag())
+	}
+	require.ElementsMatch(t, []string{
+		"DiveDto.Emails[1] email",
+		"DiveDto.Scores[a] min",
+		"DiveDto.Scores[bc] gt",
+		"DiveDto.Address.City required",
+		"DiveDto.Address.Zip len",
+		"DiveDto.Addresses[1].City required",
+		"DiveDto.Pointer.City required",
+		"DiveDto.Nested[0][0] min",
+		"DiveDto.Map[a].City required",
+	}, namespaces)
+
+	require.ErrorContains(t, validate.Struct(&DiveDto{Addresses: mo.Some([]DiveAddress{})}), "'Addresses' failed on the 'min' tag")
+}
+
+type TagNameDto struct {
+	Name   mo.Option[string] `json:"nick_name" validate:"present"`
+	Page   mo.Option[int]    `json:"-" form:"page" validate:"present"`
+	ID     mo.Option[int64]  `uri:"id" validate:"present"`
+	Token  mo.Option[string] `header:"X-Token" validate:"present"`
+	Plain  mo.Option[string] `json:",omitempty" form:"plain" validate:"present"`
+	Ignore mo.Option[string] `json:"-" validate:"present"`
+	Email  mo.Option[string] `json:"email"`
+	Phone  mo.Option[string] `json:"phone"`
+}
+
+func TestGoPlaygroundTagName(t *testing.T) {
+	validate := validator.New()
+	require.NoError(t, RegisterGPValidatorPresent(validate))
+	RegisterGPTagNameFunc(validate)
+	validate.RegisterStructValidation(GPExactlyOnePresent("Email", "Phone"), TagNameDto{})
+
+	err := validate.Struct(&TagNameDto{})
+	require.Error(t, err)
+	var fields []string
+	for _, fe := range err.(validator.ValidationErrors) {
+		fields = append(fields, fe.Field()+" "+fe.StructField())
+	}
+	require.Equal(t, []string{
+		"nick_name Name",
+		"page Page",
+		"id ID",
+		"X-Token Token",
+		"plain Plain",
+		"Ignore Ignore",
+		"email Email",
+	}, fields)
+	require.ErrorContains(t, err, "Key: 'TagNameDto.nick_name' Error:Field validation for 'nick_name' failed on the 'present' tag")
+
+	// without GPTagNameFunc, the struct level error use the go field name
+	validate = validator.New()
+	require.NoError(t, RegisterGPValidatorPresent(validate))
+	validate.RegisterStructValidation(GPExactlyOnePresent("Email", "Phone"), TagNameDto{})
+	require.ErrorContains(t, validate.Struct(&TagNameDto{}), "Key: 'TagNameDto.Email' Error:Field validation for 'Email' failed on the 'exactly_one_present' tag")
+}
+
+type tenantKey struct{}
+
+type ContextDto struct {
+	Tenant mo.Option[string] `validate:"omitnone,tenant"`
+}
+
+func TestGoPlaygroundContext(t *testing.T) {
+	validate := validator.New()
+	RegisterGPVOption[string](validate)
+	RegisterGPValidatorOmitNone(validate)
+	require.NoError(t, RegisterGPContextValidator(validate, "tenant", tenantKey{}, func(fl validator.FieldLevel, tenant string) bool {
+		return fl.Field().String() == tenant
+	}))
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "a")
+	require.NoError(t, validate.StructCtx(ctx, ContextDto{}))
+	require.NoError(t, validate.StructCtx(ctx, ContextDto{Tenant: mo.Some("a")}))
+	require.ErrorContains(t, validate.StructCtx(ctx, ContextDto{Tenant: mo.Some("b")}), "'tenant' tag")
+	// absent in context
+	require.ErrorContains(t, validate.StructCtx(context.Background(), ContextDto{Tenant: mo.Some("a")}), "'tenant' tag")
+	require.ErrorContains(t, validate.Struct(ContextDto{Tenant: mo.Some("a")}), "'tenant' tag")
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Set("tenant", "a")
+	value, ok := GPContextValue[string](c, "tenant")
+	require.True(t, ok)
+	require.Equal(t, "a", value)
+	_, ok = GPContextValue[int](c, "tenant")
+	require.False(t, ok)
+	_, ok = GPContextValue[string](c.Request.Context(), "tenant")
+	require.False(t, ok)
+	value, ok = GPContextValue[string](context.WithValue(c.Request.Context(), gin.ContextKey, c), "tenant")
+	require.True(t, ok)
+	require.Equal(t, "a", value)
+}
+
+type PresentNilDto struct {
+	DeletedAt mo.Option[*time.Time]  `validate:"present"`
+	Names     []mo.Option[string]    `validate:"dive,present"`
+	Ptr       *mo.Option[*time.Time] `validate:"present"`
+}
+
+func TestGoPlaygroundPresentNil(t *testing.T) {
+	validate := validator.New()
+	require.NoError(t, RegisterGPValidatorPresent(validate))
+	RegisterGPVOptionOf(validate, PresentNilDto{})
+	now := time.Now()
+
+	require.NoError(t, validate.Struct(PresentNilDto{DeletedAt: mo.Some[*time.Time](nil), Ptr: lo.ToPtr(mo.Some[*time.Time](nil))}))
+	require.NoError(t, validate.Struct(PresentNilDto{
+		DeletedAt: mo.Some(&now),
+		Names:     []mo.Option[string]{mo.Some("")},
+		Ptr:       lo.ToPtr(mo.Some(&now)),
+	}))
+	err := validate.Struct(PresentNilDto{Names: []mo.Option[string]{mo.Some("a"), mo.None[string]()}, Ptr: lo.ToPtr(mo.None[*time.Time]())})
+	require.ErrorContains(t, err, "'PresentNilDto.DeletedAt' Error:Field validation for 'DeletedAt' failed on the 'present' tag")
+	require.ErrorContains(t, err, "'PresentNilDto.Names[1]' Error:Field validation for 'Names[1]' failed on the 'present' tag")
+	require.ErrorContains(t, err, "'PresentNilDto.Ptr' Error:Field validation for 'Ptr' failed on the 'present' tag")
+	require.NotContains(t, err.Error(), "Names[0]")
+
+	type BindingDto struct {
+		DeletedAt mo.Option[*time.Time] `binding:"present"`
+	}
+	v := NewValidator()
+	require.NoError(t, v.ValidateStruct(BindingDto{DeletedAt: mo.Some[*time.Time](nil)}))
+	require.ErrorContains(t, v.ValidateStruct(BindingDto{}), "'present' tag")
+}
+
+func TestGoPlaygroundNotNilOption(t *testing.T) {
+	type NotNilDto struct {
+		V mo.Option[*string] `validate:"notnil"`
+	}
+	str := ""
+	plain := validator.New()
+	require.NoError(t, RegisterGPValidatorNotNil(plain))
+	unwrapped := validator.New()
+	require.NoError(t, RegisterGPValidatorNotNil(unwrapped))
+	RegisterGPVOptionOf(unwrapped, NotNilDto{})
+	for _, validate := range []*validator.Validate{plain, unwrapped} {
+		require.NoError(t, validate.Struct(NotNilDto{V: mo.Some(&str)}))
+		require.ErrorContains(t, validate.Struct(NotNilDto{V: mo.Some[*string](nil)}), "'V' failed on the 'notnil' tag")
+		require.ErrorContains(t, validate.Struct(NotNilDto{}), "'V' failed on the 'notnil' tag")
+	}
+}
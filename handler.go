@@ -0,0 +1,154 @@
+package mox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// ErrBind the error of binding or validating request in Handle.
+	ErrBind = errors.New("bind request")
+)
+
+// HandleError the default error handler of Handle, see WithErrorHandler.
+// 400 for ErrBind, 500 for other errors, the body is {"error": "message"}.
+func HandleError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, ErrBind) {
+		status = http.StatusBadRequest
+	}
+	c.AbortWithStatusJSON(status, gin.H{"error": err.Error()})
+}
+
+// HandleOption the option of Handle.
+type HandleOption func(config *handleConfig)
+
+type handleConfig struct {
+	errorHandler func(c *gin.Context, err error)
+}
+
+// WithErrorHandler render the error of Handle by fn instead of HandleError, like mox.Problem.
+func WithErrorHandler(fn func(c *gin.Context, err error)) HandleOption {
+	return func(config *handleConfig) {
+		config.errorHandler = fn
+	}
+}
+
+// Handle adapt the typed function to gin.HandlerFunc, ctx is the *gin.Context.
+//  1. bind Req by BindRequest, and validate it, the error is wrapped by ErrBind.
+//  2. call fn, and render the response as json by OptionJSON, with status 200.
+//  3. the error is rendered by HandleError, or the handler of WithErrorHandler.
+func Handle[Req, Resp any](fn func(ctx context.Context, req Req) (Resp, error), opts ...HandleOption) gin.HandlerFunc {
+	return HandleScenario("", fn, opts...)
+}
+
+// HandleScenario same as Handle, but validate Req in the scenario, see ValidateScenario.
+func HandleScenario[Req, Resp any](scenario string, fn func(ctx context.Context, req Req) (Resp, error), opts ...HandleOption) gin.HandlerFunc {
+	config := handleConfig{errorHandler: HandleError}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return func(c *gin.Context) {
+		var req Req
+		target := any(&req)
+		if t := reflect.TypeOf(req); t != nil && t.Kind() == reflect.Ptr {
+			// Req is a pointer to struct
+			v := reflect.New(t.Elem())
+			reflect.ValueOf(&req).Elem().Set(v)
+			target = req
+		}
+		if err := BindRequestScenario(c, target, scenario); err != nil {
+			_ = c.Error(err)
+			config.errorHandler(c, err)
+			return
+		}
+		resp, err := fn(c, req)
+		if err != nil {
+			_ = c.Error(err)
+			config.errorHandler(c, err)
+			return
+		}
+		c.Render(http.StatusOK, OptionJSONRender{Data: resp})
+	}
+}
+
+// BindRequest bind obj from uri, header, query and body, then validate it with c as context, the error is wrapped by ErrBind.
+//   - uri tag: path params.
+//   - header tag: headers.
+//   - form tag: query, and form body for POST, PUT, PATCH.
+//   - json body: decode by OptionJSON.
+//
+// only the fields with the tag are bound from path params, headers and forms, so a stray header or query
+// can not fill the field of json body. path params and headers are bound after the body, so they win.
+func BindRequest(c *gin.Context, obj any) error {
+	return BindRequestScenario(c, obj, "")
+}
+
+// BindRequestScenario same as BindRequest, but validate obj in the scenario, see ValidateScenario.
+func BindRequestScenario(c *gin.Context, obj any, scenario string) error {
+	if err := bindRequest(c, obj); err != nil {
+		return fmt.Errorf("%w: %w", ErrBind, err)
+	}
+	if err := ValidateScenarioCtx(c, obj, scenario); err != nil {
+		return fmt.Errorf("%w: %w", ErrBind, err)
+	}
+	return nil
+}
+
+func bindRequest(c *gin.Context, obj any) error {
+	req := c.Request
+	if err := mapFormByTag(obj, req.URL.Query(), "form", true); err != nil {
+		return err
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.ContentLength != 0 && req.Method != http.MethodGet && req.Method != http.MethodHead {
+		switch c.ContentType() {
+		case gin.MIMEJSON:
+			if err := OptionJSON.NewDecoder(req.Body).Decode(obj); err != nil {
+				return err
+			}
+		case gin.MIMEPOSTForm, gin.MIMEMultipartPOSTForm:
+			if err := req.ParseMultipartForm(defaultMemory); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+				return err
+			}
+			if err := mapFormByTag(obj, req.PostForm, "form", true); err != nil {
+				return err
+			}
+		}
+	}
+	// path params and headers are bound last, so the body can not override them.
+	params := make(map[string][]string, len(c.Params))
+	for _, param := range c.Params {
+		params[param.Key] = []string{param.Value}
+	}
+	if err := mapFormByTag(obj, params, "uri", true); err != nil {
+		return err
+	}
+	return mapFormByTag(obj, req.Header, "header", true)
+}
+
+// OptionJSONRender render the data as json by OptionJSON.
+type OptionJSONRender struct {
+	Data any
+}
+
+func (r OptionJSONRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	b, err := OptionJSON.Marshal(r.Data)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+func (r OptionJSONRender) WriteContentType(w http.ResponseWriter) {
+	header := w.Header()
+	if len(header["Content-Type"]) == 0 {
+		header["Content-Type"] = []string{"application/json; charset=utf-8"}
+	}
+}
@@ -0,0 +1,124 @@
+package mox
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+)
+
+type HandleUpdateReq struct {
+	ID    int64              `uri:"id"`
+	Token string             `header:"x-token" binding:"required"`
+	Dry   mo.Option[bool]    `form:"dry" json:"-"`
+	Name  mo.Option[string]  `json:"name"`
+	Email mo.Option[*string] `json:"email"`
+}
+
+type HandleUpdateResp struct {
+	ID    int64              `json:"id"`
+	Name  mo.Option[string]  `json:"name,omitempty"`
+	Email mo.Option[*string] `json:"email,omitempty"`
+	Dry   bool               `json:"dry"`
+}
+
+type HandleStrayReq struct {
+	ID     int
+	Name   mo.Option[string] `json:"name,omitempty"`
+	Origin mo.Option[string] `json:"origin,omitempty"`
+}
+
+var errHandleNotFound = errors.New("not found")
+
+func TestHandle(t *testing.T) {
+	engine := gin.New()
+	engine.PATCH("/users/:id", Handle(func(ctx context.Context, req HandleUpdateReq) (HandleUpdateResp, error) {
+		require.Equal(t, "token", ctx.(*gin.Context).GetHeader("X-Token"))
+		if req.ID == 0 {
+			return HandleUpdateResp{}, errHandleNotFound
+		}
+		return HandleUpdateResp{ID: req.ID, Name: req.Name, Email: req.Email, Dry: req.Dry.OrEmpty()}, nil
+	}))
+	engine.POST("/users", Handle(func(ctx context.Context, req *HandleUpdateReq) ([]int64, error) {
+		return []int64{req.ID}, nil
+	}))
+
+	serve := func(method, path string, dto any) *httptest.ResponseRecorder {
+		req, err := NewRequest(method, path, dto)
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(http.MethodPatch, "/users/:id?dry=true", HandleUpdateReq{ID: 1, Token: "token", Email: mo.Some[*string](nil)})
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	require.JSONEq(t, `{"id": 1, "email": null, "dry": true}`, w.Body.String())
+
+	w = serve(http.MethodPatch, "/users/:id", HandleUpdateReq{ID: 1, Name: mo.Some("sb")})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "'required' tag")
+
+	w = serve(http.MethodPatch, "/users/:id", HandleUpdateReq{ID: 0, Token: "token"})
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.JSONEq(t, `{"error": "not found"}`, w.Body.String())
+
+	w = serve(http.MethodPost, "/users", struct {
+		Token string `header:"X-Token"`
+	}{Token: "token"})
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `[0]`, w.Body.String())
+
+	req := httptest.NewRequest(http.MethodPatch, "/users/1", nil)
+	req.Header.Set("X-Token", "token")
+	req.Header.Set("Content-Type", gin.MIMEJSON)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// the fields without uri, header or form tag are not bound from path params, headers and query
+	engine.PATCH("/u/:id", Handle(func(ctx context.Context, req HandleStrayReq) (HandleStrayReq, error) {
+		return req, nil
+	}))
+	req = httptest.NewRequest(http.MethodPatch, "/u/1?Name=q&ID=2", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", gin.MIMEJSON)
+	req.Header.Set("Origin", "https://evil.example")
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"ID": 0}`, w.Body.String())
+
+	// the body can not override the path param and header
+	req = httptest.NewRequest(http.MethodPatch, "/users/1", strings.NewReader(`{"ID":999,"Token":"evil","name":"sb"}`))
+	req.Header.Set("X-Token", "token")
+	req.Header.Set("Content-Type", gin.MIMEJSON)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"id": 1, "name": "sb", "dry": false}`, w.Body.String())
+
+	// the error handler of route
+	engine.DELETE("/users/:id", Handle(func(ctx context.Context, req HandleUpdateReq) (HandleUpdateResp, error) {
+		return HandleUpdateResp{}, errHandleNotFound
+	}, WithErrorHandler(func(c *gin.Context, err error) {
+		if errors.Is(err, errHandleNotFound) {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		HandleError(c, err)
+	})))
+	w = serve(http.MethodDelete, "/users/:id", HandleUpdateReq{ID: 1, Token: "token"})
+	require.Equal(t, http.StatusNotFound, w.Code)
+	w = serve(http.MethodDelete, "/users/:id", HandleUpdateReq{ID: 1})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	// other routes are not affected
+	w = serve(http.MethodPatch, "/users/:id", HandleUpdateReq{ID: 0, Token: "token"})
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -4,43 +4,284 @@
 package mox
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
 	"reflect"
 	"strings"
 	"unsafe"
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/modern-go/reflect2"
+	"github.com/samber/lo"
 )
 
+// MoxTagNull tag `mox:"null"`, encode the absent option as null, even with omitempty.
+const MoxTagNull = "null"
+
+// OptionJSON the jsoniter API compatible with encoding/json, which registered OptionExtension.
+var OptionJSON = newOptionJSON()
+
+func newOptionJSON() jsoniter.API {
+	api := jsoniter.Config{
+		EscapeHTML:             true,
+		SortMapKeys:            true,
+		ValidateJsonRawMessage: true,
+	}.Froze()
+	api.RegisterExtension(&OptionExtension{})
+	return api
+}
+
 type OptionExtension struct {
 	jsoniter.DummyExtension
+	// KeepMapNone encode the absent option value of map as null, default omit the entry.
+	// it also works with the OptionExtension registered globally by jsoniter.RegisterExtension,
+	// but can not be turned off per API if the global one is KeepMapNone.
+	KeepMapNone bool
 }
 
 func (ext *OptionExtension) CreateEncoder(typ reflect2.Type) jsoniter.ValEncoder {
-	typeName := typ.String()
-	if strings.HasPrefix(typeName, "mo.Option[") {
-		return &OptionEncoder{typ: typ}
+	if info, ok := OptionOf(typ.Type1()); ok {
+		return &OptionEncoder{typ: typ, info: info}
+	}
+	switch typ.Kind() {
+	case reflect.Slice:
+		if info, ok := OptionOf(typ.Type1().Elem()); ok {
+			return &optionSliceEncoder{typ: typ, info: info}
+		}
+	case reflect.Map:
+		if info, ok := OptionOf(typ.Type1().Elem()); ok {
+			return &optionMapEncoder{typ: typ, info: info, keepNone: ext.KeepMapNone}
+		}
+	}
+	return nil
+}
+
+// DecorateEncoder apply KeepMapNone to the map encoder created by other OptionExtension, like the global one,
+// because jsoniter use the encoder of the first extension.
+func (ext *OptionExtension) DecorateEncoder(typ reflect2.Type, encoder jsoniter.ValEncoder) jsoniter.ValEncoder {
+	if mapEncoder, ok := encoder.(*optionMapEncoder); ok && ext.KeepMapNone && !mapEncoder.keepNone {
+		return &optionMapEncoder{typ: mapEncoder.typ, info: mapEncoder.info, keepNone: true}
+	}
+	return encoder
+}
+
+func (ext *OptionExtension) CreateDecoder(typ reflect2.Type) jsoniter.ValDecoder {
+	// the pointer is decoded by jsoniter with the decoder of its element.
+	if info, ok := OptionOf(typ.Type1()); ok && !info.Pointer && !reflect.PointerTo(typ.Type1()).Implements(jsonUnmarshalerType) {
+		return &optionDecoder{typ: typ, info: info}
+	}
+	switch typ.Kind() {
+	case reflect.Slice:
+		if info, ok := OptionOf(typ.Type1().Elem()); ok {
+			return &optionSliceDecoder{typ: typ, info: info}
+		}
+	case reflect.Map:
+		if info, ok := OptionOf(typ.Type1().Elem()); ok {
+			return &optionMapDecoder{typ: typ, info: info}
+		}
 	}
 	return nil
 }
 
+// UpdateStructDescriptor apply the option field tag `mox`.
+func (ext *OptionExtension) UpdateStructDescriptor(structDescriptor *jsoniter.StructDescriptor) {
+	for _, binding := range structDescriptor.Fields {
+		info, ok := OptionOf(binding.Field.Type().Type1())
+		if !ok {
+			continue
+		}
+		moxTags := strings.Split(binding.Field.Tag().Get("mox"), ",")
+		if lo.Contains(moxTags, MoxTagNull) {
+			binding.Encoder = &OptionEncoder{typ: binding.Field.Type(), info: info, null: true}
+		}
+	}
+}
+
 type OptionEncoder struct {
-	typ reflect2.Type
+	typ  reflect2.Type
+	info OptionInfo
+	// null encode absent as null, and never be empty.
+	null bool
 }
 
 func (encoder *OptionEncoder) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
-	obj := encoder.typ.UnsafeIndirect(ptr)
-	reflectValue := reflect.ValueOf(obj)
-	// when IsEmpty=true, will not call Encode, so directly call get here.
-	orEmptyMethod := reflectValue.MethodByName("OrEmpty")
-	value := orEmptyMethod.Call([]reflect.Value{})[0].Interface()
-	stream.WriteVal(value)
+	// when IsEmpty=true, will not call Encode, so directly write the value or empty value here.
+	v := encoder.value(ptr)
+	if encoder.info.Pointer && v.IsNil() {
+		stream.WriteNil()
+		return
+	}
+	value, ok := encoder.info.get(v)
+	if !ok && encoder.null {
+		stream.WriteNil()
+		return
+	}
+	stream.WriteVal(value.Interface())
 }
 
 func (encoder *OptionEncoder) IsEmpty(ptr unsafe.Pointer) bool {
-	obj := encoder.typ.UnsafeIndirect(ptr)
-	reflectValue := reflect.ValueOf(obj)
-	isPresentMethod := reflectValue.MethodByName("IsPresent")
-	isPresent := isPresentMethod.Call(nil)[0].Bool()
-	return !isPresent
+	if encoder.null {
+		return false
+	}
+	return !encoder.info.isPresent(encoder.value(ptr))
+}
+
+func (encoder *OptionEncoder) value(ptr unsafe.Pointer) reflect.Value {
+	return reflect.NewAt(encoder.typ.Type1(), ptr).Elem()
+}
+
+// optionSliceEncoder encode the absent option element as null.
+type optionSliceEncoder struct {
+	typ  reflect2.Type
+	info OptionInfo
+}
+
+func (encoder *optionSliceEncoder) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
+	v := reflect.NewAt(encoder.typ.Type1(), ptr).Elem()
+	if v.IsNil() {
+		stream.WriteNil()
+		return
+	}
+	stream.WriteArrayStart()
+	for i := range v.Len() {
+		if i > 0 {
+			stream.WriteMore()
+		}
+		if value, ok := encoder.info.get(v.Index(i)); ok {
+			stream.WriteVal(value.Interface())
+		} else {
+			stream.WriteNil()
+		}
+	}
+	stream.WriteArrayEnd()
+}
+
+func (encoder *optionSliceEncoder) IsEmpty(ptr unsafe.Pointer) bool {
+	return reflect.NewAt(encoder.typ.Type1(), ptr).Elem().Len() == 0
+}
+
+// optionMapEncoder omit the entry of absent option value, or encode it as null when keepNone=true.
+type optionMapEncoder struct {
+	typ      reflect2.Type
+	info     OptionInfo
+	keepNone bool
+}
+
+func (encoder *optionMapEncoder) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
+	v := reflect.NewAt(encoder.typ.Type1(), ptr).Elem()
+	if v.IsNil() {
+		stream.WriteNil()
+		return
+	}
+	// let jsoniter encode the keys, so the key and sort behavior is same as normal map.
+	values := reflect.MakeMapWithSize(reflect.MapOf(v.Type().Key(), reflect.TypeOf((*any)(nil)).Elem()), v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		value, ok := encoder.info.get(iter.Value())
+		switch {
+		case ok:
+			values.SetMapIndex(iter.Key(), value)
+		case encoder.keepNone:
+			values.SetMapIndex(iter.Key(), reflect.Zero(values.Type().Elem()))
+		}
+	}
+	stream.WriteVal(values.Interface())
+}
+
+func (encoder *optionMapEncoder) IsEmpty(ptr unsafe.Pointer) bool {
+	return reflect.NewAt(encoder.typ.Type1(), ptr).Elem().Len() == 0
+}
+
+// optionDecoder decode the option without UnmarshalJSON, like `type MaybeName mo.Option[string]`,
+// same as mo.Option.UnmarshalJSON, null is present with zero value.
+type optionDecoder struct {
+	typ  reflect2.Type
+	info OptionInfo
+}
+
+func (decoder *optionDecoder) Decode(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+	value := reflect.New(decoder.info.Elem)
+	iter.ReadVal(value.Interface())
+	if iter.Error != nil {
+		return
+	}
+	decoder.info.set(reflect.NewAt(decoder.typ.Type1(), ptr).Elem(), value.Elem())
+}
+
+// optionSliceDecoder decode null element as absent option.
+type optionSliceDecoder struct {
+	typ  reflect2.Type
+	info OptionInfo
+}
+
+func (decoder *optionSliceDecoder) Decode(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+	v := reflect.NewAt(decoder.typ.Type1(), ptr).Elem()
+	if iter.ReadNil() {
+		v.Set(reflect.Zero(v.Type()))
+		return
+	}
+	var raws []json.RawMessage
+	iter.ReadVal(&raws)
+	if iter.Error != nil {
+		return
+	}
+	slice := reflect.MakeSlice(v.Type(), len(raws), len(raws))
+	for i, raw := range raws {
+		if err := decodeOptionRaw(iter.Pool(), decoder.info, slice.Index(i), raw); err != nil {
+			iter.ReportError("decode option slice", err.Error())
+			return
+		}
+	}
+	v.Set(slice)
+}
+
+// optionMapDecoder decode null value as absent option.
+type optionMapDecoder struct {
+	typ  reflect2.Type
+	info OptionInfo
+}
+
+func (decoder *optionMapDecoder) Decode(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+	v := reflect.NewAt(decoder.typ.Type1(), ptr).Elem()
+	if iter.ReadNil() {
+		v.Set(reflect.Zero(v.Type()))
+		return
+	}
+	raws := reflect.New(reflect.MapOf(v.Type().Key(), reflect.TypeOf(json.RawMessage{})))
+	iter.ReadVal(raws.Interface())
+	if iter.Error != nil {
+		return
+	}
+	if v.IsNil() {
+		v.Set(reflect.MakeMapWithSize(v.Type(), raws.Elem().Len()))
+	}
+	rawIter := raws.Elem().MapRange()
+	for rawIter.Next() {
+		value := reflect.New(v.Type().Elem()).Elem()
+		if err := decodeOptionRaw(iter.Pool(), decoder.info, value, rawIter.Value().Bytes()); err != nil {
+			iter.ReportError("decode option map", err.Error())
+			return
+		}
+		v.SetMapIndex(rawIter.Key(), value)
+	}
+}
+
+// decodeOptionRaw null as absent, else present.
+func decodeOptionRaw(pool jsoniter.IteratorPool, info OptionInfo, option reflect.Value, raw []byte) error {
+	// jsoniter decode null to empty json.RawMessage
+	if len(raw) == 0 || string(raw) == "null" {
+		info.unset(option)
+		return nil
+	}
+	iter := pool.BorrowIterator(raw)
+	defer pool.ReturnIterator(iter)
+	value := reflect.New(info.Elem)
+	iter.ReadVal(value.Interface())
+	// same as jsoniter.API.Unmarshal, reach the end of raw is not an error.
+	if iter.Error != nil && !errors.Is(iter.Error, io.EOF) {
+		return iter.Error
+	}
+	info.set(option, value.Elem())
+	return nil
 }
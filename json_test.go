@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 
 	jsoniter "github.com/json-iterator/go"
+	"github.com/samber/lo"
 	"github.com/samber/mo"
 )
 
@@ -51,3 +53,90 @@ func TestGoJson(t *testing.T) {
 	require.Equal(t, "", user2.Name.OrEmpty())
 	require.Equal(t, "", user22.Name.OrEmpty())
 }
+
+func TestJsoniterNull(t *testing.T) {
+	jsoniterApi := jsoniter.Config{}.Froze()
+	jsoniterApi.RegisterExtension(&OptionExtension{})
+	type User struct {
+		Name      mo.Option[string]    `json:"name,omitempty"`
+		DeletedAt mo.Option[time.Time] `json:"deleted_at,omitempty" mox:"null"`
+		Age       mo.Option[int]       `json:"age" mox:"null"`
+	}
+	b, err := jsoniterApi.Marshal(User{})
+	require.NoError(t, err)
+	require.Equal(t, `{"deleted_at":null,"age":null}`, string(b))
+
+	deletedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	b, err = jsoniterApi.Marshal(User{Name: mo.Some("sb"), DeletedAt: mo.Some(deletedAt), Age: mo.Some(0)})
+	require.NoError(t, err)
+	require.Equal(t, `{"name":"sb","deleted_at":"2024-01-02T03:04:05Z","age":0}`, string(b))
+}
+
+func TestJsoniterOptionCollection(t *testing.T) {
+	jsoniterApi := jsoniter.Config{SortMapKeys: true}.Froze()
+	jsoniterApi.RegisterExtension(&OptionExtension{})
+	type Dto struct {
+		Names []mo.Option[string]          `json:"names"`
+		Ages  map[string]mo.Option[int]    `json:"ages"`
+		Tags  map[int]Option[string]       `json:"tags,omitempty"`
+		Nums  [][]mo.Option[int]           `json:"nums,omitempty"`
+		Ptrs  map[string]*mo.Option[int64] `json:"ptrs,omitempty"`
+	}
+	dto := Dto{
+		Names: []mo.Option[string]{mo.Some("a"), mo.None[string](), mo.Some("")},
+		Ages:  map[string]mo.Option[int]{"a": mo.Some(1), "b": mo.None[int](), "c": mo.Some(0)},
+		Tags:  map[int]Option[string]{1: Some("x")},
+		Nums:  [][]mo.Option[int]{{mo.None[int](), mo.Some(1)}},
+	}
+	b, err := jsoniterApi.Marshal(dto)
+	require.NoError(t, err)
+	require.Equal(t, `{"names":["a",null,""],"ages":{"a":1,"c":0},"tags":{"1":"x"},"nums":[[null,1]]}`, string(b))
+
+	var dto2 Dto
+	require.NoError(t, jsoniterApi.Unmarshal(b, &dto2))
+	delete(dto.Ages, "b")
+	require.Equal(t, dto, dto2)
+
+	var dto3 Dto
+	require.NoError(t, jsoniterApi.Unmarshal([]byte(`{"names":null,"ages":{"a":null,"b":2}}`), &dto3))
+	require.Nil(t, dto3.Names)
+	require.Equal(t, map[string]mo.Option[int]{"a": mo.None[int](), "b": mo.Some(2)}, dto3.Ages)
+
+	keepNoneApi := jsoniter.Config{SortMapKeys: true}.Froze()
+	keepNoneApi.RegisterExtension(&OptionExtension{KeepMapNone: true})
+	b, err = keepNoneApi.Marshal(dto3)
+	require.NoError(t, err)
+	require.Equal(t, `{"names":null,"ages":{"a":null,"b":2}}`, string(b))
+	var dto4 Dto
+	require.NoError(t, keepNoneApi.Unmarshal(b, &dto4))
+	require.Equal(t, dto3, dto4)
+}
+
+func TestJsoniterOptionDecode(t *testing.T) {
+	type Dto struct {
+		Name  MaybeName          `json:"name,omitempty"`
+		Nick  *mo.Option[string] `json:"nick,omitempty"`
+		Embed EmbedName          `json:"embed,omitempty"`
+		Alias *MaybeName         `json:"alias,omitempty"`
+	}
+	dto := Dto{
+		Name:  MaybeName(mo.Some("x")),
+		Nick:  lo.ToPtr(mo.Some("y")),
+		Embed: EmbedName{mo.Some("z")},
+		Alias: lo.ToPtr(MaybeName(mo.Some(""))),
+	}
+	b, err := OptionJSON.Marshal(dto)
+	require.NoError(t, err)
+	require.Equal(t, `{"name":"x","nick":"y","embed":"z","alias":""}`, string(b))
+	var dto2 Dto
+	require.NoError(t, OptionJSON.Unmarshal(b, &dto2))
+	require.Equal(t, dto, dto2)
+
+	var dto3 Dto
+	require.NoError(t, OptionJSON.Unmarshal([]byte(`{}`), &dto3))
+	require.Equal(t, Dto{}, dto3)
+	// same as mo.Option, null is present
+	require.NoError(t, OptionJSON.Unmarshal([]byte(`{"name":null,"embed":null}`), &dto3))
+	require.Equal(t, Dto{Name: MaybeName(mo.Some("")), Embed: EmbedName{mo.Some("")}}, dto3)
+	require.Error(t, OptionJSON.Unmarshal([]byte(`{"name":1}`), &dto3))
+}
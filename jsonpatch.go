@@ -0,0 +1,462 @@
+package mox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrJSONPatchOperation  = errors.New("invalid json patch operation")
+	ErrJSONPatchPath       = errors.New("json patch path not found")
+	ErrJSONPatchTestFailed = errors.New("json patch test failed")
+)
+
+// JSONPatchOperation the operation of JSON Patch (RFC 6902).
+type JSONPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// ApplyJSONPatch apply JSON Patch (RFC 6902) to dst, the path is JSON Pointer (RFC 6901) of json tag names.
+// all operations are applied or none of them.
+//   - absent option not exist, so remove/replace/test/move/copy on it will fail.
+//   - add/replace on option set Some(value), remove on option set None.
+//   - remove on other struct field set the zero value.
+func ApplyJSONPatch(dst any, ops []byte) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("%w: %T", ErrPatchTarget, dst)
+	}
+	var operations []JSONPatchOperation
+	if err := json.Unmarshal(ops, &operations); err != nil {
+		return fmt.Errorf("%w: %w", ErrJSONPatchOperation, err)
+	}
+	doc := deepCopy(v.Elem())
+	for i, operation := range operations {
+		if err := applyJSONPatchOperation(doc, operation); err != nil {
+			return fmt.Errorf("operation %d %s %s: %w", i, operation.Op, operation.Path, err)
+		}
+	}
+	v.Elem().Set(doc)
+	return nil
+}
+
+func applyJSONPatchOperation(doc reflect.Value, operation JSONPatchOperation) error {
+	tokens, err := parseJSONPointer(operation.Path)
+	if err != nil {
+		return err
+	}
+	switch operation.Op {
+	case "add", "replace":
+		if operation.Value == nil {
+			return fmt.Errorf("%w: missing value", ErrJSONPatchOperation)
+		}
+		return setJSONPointer(doc, tokens, operation.Value, operation.Op == "add")
+	case "remove":
+		return removeJSONPointer(doc, tokens)
+	case "move", "copy":
+		from, err := parseJSONPointer(operation.From)
+		if err != nil {
+			return err
+		}
+		if operation.Op == "move" && operation.From != operation.Path && strings.HasPrefix(operation.Path, operation.From+"/") {
+			return fmt.Errorf("%w: can not move %s to its child", ErrJSONPatchOperation, operation.From)
+		}
+		value, err := getJSONPointer(doc, from)
+		if err != nil {
+			return err
+		}
+		if operation.Op == "move" {
+			if err := removeJSONPointer(doc, from); err != nil {
+				return err
+			}
+		}
+		return setJSONPointer(doc, tokens, value, true)
+	case "test":
+		if operation.Value == nil {
+			return fmt.Errorf("%w: missing value", ErrJSONPatchOperation)
+		}
+		value, err := getJSONPointer(doc, tokens)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrJSONPatchTestFailed, err)
+		}
+		var expected, actual any
+		if err := json.Unmarshal(operation.Value, &expected); err != nil {
+			return fmt.Errorf("%w: %w", ErrJSONPatchOperation, err)
+		}
+		if err := json.Unmarshal(value, &actual); err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			return fmt.Errorf("%w: expected %s, actual %s", ErrJSONPatchTestFailed, operation.Value, value)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown op %q", ErrJSONPatchOperation, operation.Op)
+	}
+}
+
+// parseJSONPointer parse JSON Pointer (RFC 6901), "" is the whole document.
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: invalid pointer %q", ErrJSONPatchOperation, pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func getJSONPointer(doc reflect.Value, tokens []string) (json.RawMessage, error) {
+	var value reflect.Value
+	get := func(v reflect.Value) error {
+		if info, ok := OptionOf(v.Type()); ok {
+			optionValue, ok := info.get(v)
+			if !ok {
+				return fmt.Errorf("%w: option is absent", ErrJSONPatchPath)
+			}
+			v = optionValue
+		}
+		value = v
+		return nil
+	}
+	var err error
+	if len(tokens) == 0 {
+		err = get(doc)
+	} else {
+		err = walkJSONPointer(doc, tokens, func(container reflect.Value, token string) error {
+			return withJSONPointerChild(container, token, get)
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value.Interface())
+}
+
+func setJSONPointer(doc reflect.Value, tokens []string, raw json.RawMessage, add bool) error {
+	if len(tokens) == 0 {
+		return decodeJSONPatchValue(doc, raw, add)
+	}
+	return walkJSONPointer(doc, tokens, func(container reflect.Value, token string) error {
+		switch container.Kind() {
+		case reflect.Map:
+			key, err := jsonPointerMapKey(container, token)
+			if err != nil {
+				return err
+			}
+			if !add && !container.MapIndex(key).IsValid() {
+				return fmt.Errorf("%w: key %q", ErrJSONPatchPath, token)
+			}
+			value := reflect.New(container.Type().Elem()).Elem()
+			if err := decodeJSONPatchValue(value, raw, true); err != nil {
+				return err
+			}
+			if container.IsNil() {
+				container.Set(reflect.MakeMap(container.Type()))
+			}
+			container.SetMapIndex(key, value)
+			return nil
+		case reflect.Slice:
+			if !add {
+				break
+			}
+			index := container.Len()
+			if token != "-" {
+				var err error
+				if index, err = jsonPointerIndex(token, container.Len()+1); err != nil {
+					return err
+				}
+			}
+			value := reflect.New(container.Type().Elem()).Elem()
+			if err := decodeJSONPatchValue(value, raw, true); err != nil {
+				return err
+			}
+			slice := reflect.MakeSlice(container.Type(), 0, container.Len()+1)
+			slice = reflect.AppendSlice(slice, container.Slice(0, index))
+			slice = reflect.Append(slice, value)
+			slice = reflect.AppendSlice(slice, container.Slice(index, container.Len()))
+			container.Set(slice)
+			return nil
+		}
+		return withJSONPointerChild(container, token, func(v reflect.Value) error {
+			return decodeJSONPatchValue(v, raw, add)
+		})
+	})
+}
+
+func removeJSONPointer(doc reflect.Value, tokens []string) error {
+	if len(tokens) == 0 {
+		return fmt.Errorf("%w: can not remove the whole document", ErrJSONPatchOperation)
+	}
+	return walkJSONPointer(doc, tokens, func(container reflect.Value, token string) error {
+		switch container.Kind() {
+		case reflect.Map:
+			key, err := jsonPointerMapKey(container, token)
+			if err != nil {
+				return err
+			}
+			if !container.MapIndex(key).IsValid() {
+				return fmt.Errorf("%w: key %q", ErrJSONPatchPath, token)
+			}
+			container.SetMapIndex(key, reflect.Value{})
+			return nil
+		case reflect.Slice:
+			index, err := jsonPointerIndex(token, container.Len())
+			if err != nil {
+				return err
+			}
+			slice := reflect.MakeSlice(container.Type(), 0, container.Len()-1)
+			slice = reflect.AppendSlice(slice, container.Slice(0, index))
+			slice = reflect.AppendSlice(slice, container.Slice(index+1, container.Len()))
+			container.Set(slice)
+			return nil
+		}
+		return withJSONPointerChild(container, token, func(v reflect.Value) error {
+			if info, ok := OptionOf(v.Type()); ok {
+				if !info.isPresent(v) {
+					return fmt.Errorf("%w: option is absent", ErrJSONPatchPath)
+				}
+				info.unset(v)
+				return nil
+			}
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		})
+	})
+}
+
+// decodeJSONPatchValue decode raw to v, option must be present when replace.
+func decodeJSONPatchValue(v reflect.Value, raw json.RawMessage, add bool) error {
+	if info, ok := OptionOf(v.Type()); ok {
+		if !add && !info.isPresent(v) {
+			return fmt.Errorf("%w: option is absent", ErrJSONPatchPath)
+		}
+		value := reflect.New(info.Elem)
+		if err := json.Unmarshal(raw, value.Interface()); err != nil {
+			return fmt.Errorf("%w: %w", ErrPatchTypeMismatch, err)
+		}
+		info.set(v, value.Elem())
+		return nil
+	}
+	value := reflect.New(v.Type())
+	if err := json.Unmarshal(raw, value.Interface()); err != nil {
+		return fmt.Errorf("%w: %w", ErrPatchTypeMismatch, err)
+	}
+	v.Set(value.Elem())
+	return nil
+}
+
+// walkJSONPointer walk to the container of the last token, then call fn with it.
+func walkJSONPointer(v reflect.Value, tokens []string, fn func(container reflect.Value, token string) error) error {
+	return withJSONPointerContainer(v, func(container reflect.Value) error {
+		if len(tokens) == 1 {
+			return fn(container, tokens[0])
+		}
+		return withJSONPointerChild(container, tokens[0], func(child reflect.Value) error {
+			return walkJSONPointer(child, tokens[1:], fn)
+		})
+	})
+}
+
+// withJSONPointerContainer unwrap pointer, interface and option to the container, then call fn with a settable value.
+func withJSONPointerContainer(v reflect.Value, fn func(container reflect.Value) error) error {
+	if info, ok := OptionOf(v.Type()); ok {
+		current, ok := info.get(v)
+		if !ok {
+			return fmt.Errorf("%w: option is absent", ErrJSONPatchPath)
+		}
+		value := reflect.New(info.Elem).Elem()
+		value.Set(current)
+		if err := withJSONPointerContainer(value, fn); err != nil {
+			return err
+		}
+		info.set(v, value)
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return fmt.Errorf("%w: nil pointer", ErrJSONPatchPath)
+		}
+		return withJSONPointerContainer(v.Elem(), fn)
+	case reflect.Interface:
+		if v.IsNil() {
+			return fmt.Errorf("%w: nil interface", ErrJSONPatchPath)
+		}
+		value := reflect.New(v.Elem().Type()).Elem()
+		value.Set(v.Elem())
+		if err := withJSONPointerContainer(value, fn); err != nil {
+			return err
+		}
+		v.Set(value)
+		return nil
+	}
+	return fn(v)
+}
+
+// withJSONPointerChild call fn with the settable child of container by token.
+func withJSONPointerChild(container reflect.Value, token string, fn func(child reflect.Value) error) error {
+	switch container.Kind() {
+	case reflect.Struct:
+		field, ok := lookupTagField(tagFields(container.Type(), "json"), token)
+		if !ok {
+			return fmt.Errorf("%w: field %q of %s", ErrJSONPatchPath, token, container.Type())
+		}
+		return fn(container.FieldByIndex(field.Index))
+	case reflect.Map:
+		key, err := jsonPointerMapKey(container, token)
+		if err != nil {
+			return err
+		}
+		current := container.MapIndex(key)
+		if !current.IsValid() {
+			return fmt.Errorf("%w: key %q", ErrJSONPatchPath, token)
+		}
+		value := reflect.New(container.Type().Elem()).Elem()
+		value.Set(current)
+		if err := fn(value); err != nil {
+			return err
+		}
+		container.SetMapIndex(key, value)
+		return nil
+	case reflect.Slice, reflect.Array:
+		index, err := jsonPointerIndex(token, container.Len())
+		if err != nil {
+			return err
+		}
+		return fn(container.Index(index))
+	default:
+		return fmt.Errorf("%w: %q of %s", ErrJSONPatchPath, token, container.Type())
+	}
+}
+
+func jsonPointerMapKey(m reflect.Value, token string) (reflect.Value, error) {
+	keyType := m.Type().Key()
+	switch keyType.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(token).Convert(keyType), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(token, 10, keyType.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%w: key %q: %w", ErrJSONPatchPath, token, err)
+		}
+		return reflect.ValueOf(v).Convert(keyType), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(token, 10, keyType.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%w: key %q: %w", ErrJSONPatchPath, token, err)
+		}
+		return reflect.ValueOf(v).Convert(keyType), nil
+	default:
+		return reflect.Value{}, fmt.Errorf("%w: %s: %s", ErrNotSupportKind, "map key", keyType)
+	}
+}
+
+// jsonPointerIndex parse the array index, must be in [0, length).
+func jsonPointerIndex(token string, length int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index >= length || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("%w: index %q out of range %d", ErrJSONPatchPath, token, length)
+	}
+	return index, nil
+}
+
+// deepCopy copy the reachable exported values of src, so the modification of copied value will not affect src.
+// the shared pointers and maps are copied once, so the cycle is kept instead of recursing forever.
+func deepCopy(src reflect.Value) reflect.Value {
+	return deepCopyWith(src, map[copiedRef]reflect.Value{})
+}
+
+// copiedRef the pointer or map which is copied, the type is needed because a struct and its first field have same address.
+type copiedRef struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+func deepCopyWith(src reflect.Value, copied map[copiedRef]reflect.Value) reflect.Value {
+	dst := reflect.New(src.Type()).Elem()
+	dst.Set(src)
+	deepCopyInto(dst, copied)
+	return dst
+}
+
+// deepCopyInto replace the shared references of v with copies.
+func deepCopyInto(v reflect.Value, copied map[copiedRef]reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return
+		}
+		ref := copiedRef{ptr: v.Pointer(), typ: v.Type()}
+		if ptr, ok := copied[ref]; ok {
+			v.Set(ptr)
+			return
+		}
+		ptr := reflect.New(v.Type().Elem())
+		copied[ref] = ptr
+		ptr.Elem().Set(v.Elem())
+		deepCopyInto(ptr.Elem(), copied)
+		v.Set(ptr)
+		return
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		v.Set(deepCopyWith(v.Elem(), copied))
+		return
+	case reflect.Slice:
+		if v.IsNil() {
+			return
+		}
+		slice := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(slice, v)
+		for i := range slice.Len() {
+			deepCopyInto(slice.Index(i), copied)
+		}
+		v.Set(slice)
+		return
+	case reflect.Array:
+		for i := range v.Len() {
+			deepCopyInto(v.Index(i), copied)
+		}
+		return
+	case reflect.Map:
+		if v.IsNil() {
+			return
+		}
+		ref := copiedRef{ptr: v.Pointer(), typ: v.Type()}
+		if m, ok := copied[ref]; ok {
+			v.Set(m)
+			return
+		}
+		m := reflect.MakeMapWithSize(v.Type(), v.Len())
+		copied[ref] = m
+		iter := v.MapRange()
+		for iter.Next() {
+			m.SetMapIndex(iter.Key(), deepCopyWith(iter.Value(), copied))
+		}
+		v.Set(m)
+		return
+	case reflect.Struct:
+		if info, ok := OptionOf(v.Type()); ok {
+			if value, ok := info.get(v); ok {
+				info.set(v, deepCopyWith(value, copied))
+			}
+			return
+		}
+		for _, field := range tagFields(v.Type(), "json") {
+			deepCopyInto(v.FieldByIndex(field.Index), copied)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package mox
+
+import (
+	"testing"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+)
+
+type JSONPatchItem struct {
+	ID   int               `json:"id"`
+	Note mo.Option[string] `json:"note"`
+}
+
+type JSONPatchDto struct {
+	Name    string                   `json:"name"`
+	Nick    mo.Option[string]        `json:"nick_name"`
+	Deleted mo.Option[*string]       `json:"deleted_at"`
+	Tags    []string                 `json:"tags"`
+	Items   []JSONPatchItem          `json:"items"`
+	Labels  map[string]string        `json:"labels"`
+	Item    mo.Option[JSONPatchItem] `json:"item"`
+	Ptr     *JSONPatchItem           `json:"ptr"`
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	dto := JSONPatchDto{
+		Name:   "sb",
+		Tags:   []string{"a", "b"},
+		Items:  []JSONPatchItem{{ID: 1}},
+		Labels: map[string]string{"a": "1"},
+		Item:   mo.Some(JSONPatchItem{ID: 2}),
+	}
+	require.NoError(t, ApplyJSONPatch(&dto, []byte(`[
+		{"op": "test", "path": "/name", "value": "sb"},
+		{"op": "add", "path": "/nick_name", "value": "nick"},
+		{"op": "replace", "path": "/name", "value": "sb2"},
+		{"op": "add", "path": "/tags/1", "value": "c"},
+		{"op": "add", "path": "/tags/-", "value": "d"},
+		{"op": "remove", "path": "/tags/0"},
+		{"op": "add", "path": "/items/0/note", "value": "n"},
+		{"op": "add", "path": "/labels/b~1c", "value": "2"},
+		{"op": "move", "path": "/labels/c", "from": "/labels/a"},
+		{"op": "copy", "path": "/item/note", "from": "/nick_name"},
+		{"op": "add", "path": "/deleted_at", "value": null},
+		{"op": "add", "path": "/ptr", "value": {"id": 3}},
+		{"op": "replace", "path": "/ptr/id", "value": 4},
+		{"op": "test", "path": "/deleted_at", "value": null},
+		{"op": "test", "path": "/item", "value": {"id": 2, "note": "nick"}}
+	]`)))
+	require.Equal(t, JSONPatchDto{
+		Name:    "sb2",
+		Nick:    mo.Some("nick"),
+		Deleted: mo.Some[*string](nil),
+		Tags:    []string{"c", "b", "d"},
+		Items:   []JSONPatchItem{{ID: 1, Note: mo.Some("n")}},
+		Labels:  map[string]string{"b/c": "2", "c": "1"},
+		Item:    mo.Some(JSONPatchItem{ID: 2, Note: mo.Some("nick")}),
+		Ptr:     &JSONPatchItem{ID: 4},
+	}, dto)
+
+	require.NoError(t, ApplyJSONPatch(&dto, []byte(`[
+		{"op": "remove", "path": "/nick_name"},
+		{"op": "remove", "path": "/item/note"},
+		{"op": "remove", "path": "/ptr"}
+	]`)))
+	require.Equal(t, mo.None[string](), dto.Nick)
+	require.Equal(t, mo.Some(JSONPatchItem{ID: 2}), dto.Item)
+	require.Nil(t, dto.Ptr)
+
+	// test compare presence
+	require.ErrorIs(t, ApplyJSONPatch(&dto, []byte(`[{"op": "test", "path": "/nick_name", "value": ""}]`)), ErrJSONPatchTestFailed)
+	require.ErrorIs(t, ApplyJSONPatch(&dto, []byte(`[{"op": "test", "path": "/name", "value": "sb"}]`)), ErrJSONPatchTestFailed)
+	require.ErrorIs(t, ApplyJSONPatch(&dto, []byte(`[{"op": "replace", "path": "/nick_name", "value": "n"}]`)), ErrJSONPatchPath)
+	require.ErrorIs(t, ApplyJSONPatch(&dto, []byte(`[{"op": "remove", "path": "/nick_name"}]`)), ErrJSONPatchPath)
+	require.ErrorIs(t, ApplyJSONPatch(&dto, []byte(`[{"op": "add", "path": "/unknown", "value": 1}]`)), ErrJSONPatchPath)
+	require.ErrorIs(t, ApplyJSONPatch(&dto, []byte(`[{"op": "add", "path": "/tags/5", "value": "x"}]`)), ErrJSONPatchPath)
+	require.ErrorIs(t, ApplyJSONPatch(&dto, []byte(`[{"op": "add", "path": "/name", "value": 1}]`)), ErrPatchTypeMismatch)
+	require.ErrorIs(t, ApplyJSONPatch(&dto, []byte(`[{"op": "unknown", "path": "/name"}]`)), ErrJSONPatchOperation)
+
+	// atomic
+	before := dto
+	require.Error(t, ApplyJSONPatch(&dto, []byte(`[
+		{"op": "replace", "path": "/name", "value": "sb3"},
+		{"op": "add", "path": "/tags/0", "value": "x"},
+		{"op": "add", "path": "/labels/x", "value": "x"},
+		{"op": "test", "path": "/name", "value": "sb"}
+	]`)))
+	require.Equal(t, before, dto)
+	require.Equal(t, []string{"c", "b", "d"}, dto.Tags)
+	require.Equal(t, map[string]string{"b/c": "2", "c": "1"}, dto.Labels)
+}
+
+type JSONPatchNode struct {
+	Name mo.Option[string] `json:"name"`
+	Next *JSONPatchNode    `json:"next"`
+}
+
+func TestApplyJSONPatchCycle(t *testing.T) {
+	node := &JSONPatchNode{Name: mo.Some("a")}
+	node.Next = &JSONPatchNode{Name: mo.Some("b"), Next: node}
+	require.NoError(t, ApplyJSONPatch(node, []byte(`[{"op": "replace", "path": "/next/name", "value": "c"}]`)))
+	require.Equal(t, mo.Some("c"), node.Next.Name)
+	// the cycle is kept
+	require.Same(t, node.Next.Next.Next, node.Next)
+	require.Equal(t, mo.Some("a"), node.Next.Next.Name)
+}
@@ -0,0 +1,138 @@
+package mox
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// PresentPaths return the dotted paths of present options in obj, like ["name", "address.city", "items.0.note"].
+// the name of field is from json tag, then form tag, then field name.
+// nested struct, pointer, slice, array and the value of option are walked recursively.
+func PresentPaths(obj any) []string {
+	var paths []string
+	if obj == nil {
+		return paths
+	}
+	collectPresentPaths(reflect.ValueOf(obj), "", &paths)
+	return paths
+}
+
+func collectPresentPaths(v reflect.Value, path string, paths *[]string) {
+	if info, ok := OptionOf(v.Type()); ok {
+		value, ok := info.get(v)
+		if !ok {
+			return
+		}
+		if path != "" {
+			*paths = append(*paths, path)
+		}
+		collectPresentPaths(value, path, paths)
+		return
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			collectPresentPaths(v.Elem(), path, paths)
+		}
+	case reflect.Struct:
+		for _, field := range tagFields(v.Type(), "json", "form") {
+			collectPresentPaths(v.FieldByIndex(field.Index), joinMaskPath(path, field.Name), paths)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			collectPresentPaths(v.Index(i), joinMaskPath(path, strconv.Itoa(i)), paths)
+		}
+	}
+}
+
+// ApplyMask set every option which is not in the paths to None, the paths is same as PresentPaths.
+// the path keep all options under it, and the option is kept if any path is under it.
+func ApplyMask(obj any, paths []string) error {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("%w: %T", ErrPatchTarget, obj)
+	}
+	mask := make(map[string]struct{}, len(paths))
+	for _, path := range paths {
+		mask[path] = struct{}{}
+	}
+	applyMask(v.Elem(), "", mask)
+	return nil
+}
+
+func applyMask(v reflect.Value, path string, mask map[string]struct{}) {
+	if maskCovered(mask, path) {
+		return
+	}
+	if info, ok := OptionOf(v.Type()); ok {
+		current, ok := info.get(v)
+		if !ok {
+			return
+		}
+		if !maskHasChild(mask, path) {
+			info.unset(v)
+			return
+		}
+		value := reflect.New(info.Elem).Elem()
+		value.Set(current)
+		applyMask(value, path, mask)
+		info.set(v, value)
+		return
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			applyMask(v.Elem(), path, mask)
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			value := reflect.New(v.Elem().Type()).Elem()
+			value.Set(v.Elem())
+			applyMask(value, path, mask)
+			v.Set(value)
+		}
+	case reflect.Struct:
+		for _, field := range tagFields(v.Type(), "json", "form") {
+			applyMask(v.FieldByIndex(field.Index), joinMaskPath(path, field.Name), mask)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			applyMask(v.Index(i), joinMaskPath(path, strconv.Itoa(i)), mask)
+		}
+	}
+}
+
+// maskCovered report whether the path or its parent is in mask.
+func maskCovered(mask map[string]struct{}, path string) bool {
+	for path != "" {
+		if _, ok := mask[path]; ok {
+			return true
+		}
+		index := strings.LastIndexByte(path, '.')
+		if index < 0 {
+			return false
+		}
+		path = path[:index]
+	}
+	return false
+}
+
+// maskHasChild report whether any path under the path is in mask.
+func maskHasChild(mask map[string]struct{}, path string) bool {
+	prefix := path + "."
+	for p := range mask {
+		if strings.HasPrefix(p, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinMaskPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
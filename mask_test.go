@@ -0,0 +1,83 @@
+package mox
+
+import (
+	"testing"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+)
+
+type MaskAddress struct {
+	City   mo.Option[string] `json:"city"`
+	Street mo.Option[string] `form:"street"`
+}
+
+type MaskDto struct {
+	Name     mo.Option[string]      `json:"name"`
+	Nick     mo.Option[string]      `json:"-" form:"nick_name"`
+	Age      mo.Option[int]         `json:"age"`
+	Address  MaskAddress            `json:"address"`
+	Home     *MaskAddress           `json:"home"`
+	Work     mo.Option[MaskAddress] `json:"work"`
+	Items    []MaskAddress          `json:"items"`
+	Ignored  mo.Option[string]      `json:"-"`
+	NotOpt   string                 `json:"not_opt"`
+	internal mo.Option[string]
+}
+
+func TestPresentPaths(t *testing.T) {
+	dto := MaskDto{
+		Name:     mo.Some(""),
+		Nick:     mo.Some("nick"),
+		Address:  MaskAddress{City: mo.Some("c")},
+		Home:     &MaskAddress{Street: mo.Some("s")},
+		Work:     mo.Some(MaskAddress{City: mo.Some("wc")}),
+		Items:    []MaskAddress{{}, {City: mo.Some("ic")}},
+		Ignored:  mo.Some("i"),
+		NotOpt:   "n",
+		internal: mo.Some("i"),
+	}
+	require.Equal(t, []string{
+		"name",
+		"nick_name",
+		"address.city",
+		"home.street",
+		"work",
+		"work.city",
+		"items.1.city",
+	}, PresentPaths(dto))
+	require.Equal(t, PresentPaths(dto), PresentPaths(&dto))
+	require.Empty(t, PresentPaths(MaskDto{}))
+	require.Empty(t, PresentPaths(nil))
+}
+
+func TestApplyMask(t *testing.T) {
+	newDto := func() MaskDto {
+		return MaskDto{
+			Name:    mo.Some("name"),
+			Nick:    mo.Some("nick"),
+			Age:     mo.Some(1),
+			Address: MaskAddress{City: mo.Some("c"), Street: mo.Some("s")},
+			Home:    &MaskAddress{City: mo.Some("hc"), Street: mo.Some("hs")},
+			Work:    mo.Some(MaskAddress{City: mo.Some("wc"), Street: mo.Some("ws")}),
+			Items:   []MaskAddress{{City: mo.Some("ic")}, {City: mo.Some("ic2")}},
+			Ignored: mo.Some("i"),
+		}
+	}
+	dto := newDto()
+	require.NoError(t, ApplyMask(&dto, []string{"name", "address", "work.city", "items.1.city"}))
+	require.Equal(t, MaskDto{
+		Name:    mo.Some("name"),
+		Address: MaskAddress{City: mo.Some("c"), Street: mo.Some("s")},
+		Home:    &MaskAddress{},
+		Work:    mo.Some(MaskAddress{City: mo.Some("wc")}),
+		Items:   []MaskAddress{{}, {City: mo.Some("ic2")}},
+		Ignored: mo.Some("i"),
+	}, dto)
+
+	dto = newDto()
+	require.NoError(t, ApplyMask(&dto, PresentPaths(dto)))
+	require.Equal(t, newDto(), dto)
+
+	require.ErrorIs(t, ApplyMask(dto, nil), ErrPatchTarget)
+}
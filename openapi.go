@@ -0,0 +1,293 @@
+package mox
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+const openAPISchemaRefPrefix = "#/components/schemas/"
+
+var ginPathParamRegexp = regexp.MustCompile(`[:*]([^/]+)`)
+
+// OpenAPIDocument the OpenAPI 3.1 document.
+type OpenAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       OpenAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*OpenAPIOperation `json:"paths"`
+	Components OpenAPIComponents                       `json:"components"`
+}
+
+type OpenAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type OpenAPIComponents struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+type OpenAPIOperation struct {
+	OperationID string                      `json:"operationId,omitempty"`
+	Summary     string                      `json:"summary,omitempty"`
+	Tags        []string                    `json:"tags,omitempty"`
+	Parameters  []*OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+}
+
+type OpenAPIParameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Style    string  `json:"style,omitempty"`
+	Explode  *bool   `json:"explode,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type OpenAPIRequestBody struct {
+	Required bool                         `json:"required,omitempty"`
+	Content  map[string]*OpenAPIMediaType `json:"content"`
+}
+
+type OpenAPIResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*OpenAPIMediaType `json:"content,omitempty"`
+}
+
+type OpenAPIMediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// OpenAPIRoute the route and its dto types.
+type OpenAPIRoute struct {
+	Method string
+	// Path gin path like /users/:id, or OpenAPI path like /users/{id}.
+	Path        string
+	OperationID string
+	Summary     string
+	Tags        []string
+	// Request the request dto value or reflect.Type, nil if no request.
+	//   - uri tag: path parameter.
+	//   - header tag: header parameter.
+	//   - form tag: query parameter for GET, HEAD and DELETE, else form body.
+	//   - other fields: query parameter by field name for GET, HEAD and DELETE, else json body.
+	Request any
+	// Response the response dto value or reflect.Type, nil if no response body.
+	Response any
+}
+
+// OpenAPI generate the OpenAPI 3.1 document from routes, see OpenAPIRoute.
+//   - mo.Option is optional, the body schema is same as JSONSchema, which describes the json of OptionJSON.
+//   - present and notnil of validate or binding tag are required and not nullable.
+//   - min, max, len, oneof, email of validate or binding tag are the keywords of schema, for both parameters and body.
+//   - collection_format tag of slice maps to parameter style, multi(default), csv, ssv, pipes.
+type OpenAPI struct {
+	doc       *OpenAPIDocument
+	generator *schemaGenerator
+}
+
+func NewOpenAPI(title string, version string) *OpenAPI {
+	generator := newSchemaGenerator(openAPISchemaRefPrefix)
+	return &OpenAPI{
+		doc: &OpenAPIDocument{
+			OpenAPI:    "3.1.0",
+			Info:       OpenAPIInfo{Title: title, Version: version},
+			Paths:      map[string]map[string]*OpenAPIOperation{},
+			Components: OpenAPIComponents{Schemas: generator.defs},
+		},
+		generator: generator,
+	}
+}
+
+// AddGinRoutes add the registered gin routes which have dto in routes, the key of routes is "METHOD path", like "GET /users/:id".
+func (o *OpenAPI) AddGinRoutes(ginRoutes gin.RoutesInfo, routes map[string]OpenAPIRoute) {
+	for _, ginRoute := range ginRoutes {
+		route, ok := routes[ginRoute.Method+" "+ginRoute.Path]
+		if !ok {
+			continue
+		}
+		route.Method, route.Path = ginRoute.Method, ginRoute.Path
+		o.AddRoute(route)
+	}
+}
+
+// AddRoute add the operation of route.
+func (o *OpenAPI) AddRoute(route OpenAPIRoute) {
+	method := strings.ToUpper(route.Method)
+	path := ginPathParamRegexp.ReplaceAllString(route.Path, "{$1}")
+	operation := &OpenAPIOperation{
+		OperationID: route.OperationID,
+		Summary:     route.Summary,
+		Tags:        route.Tags,
+		Responses:   map[string]*OpenAPIResponse{},
+	}
+	if t := openAPIType(route.Request); t != nil {
+		o.addRequest(operation, method, t)
+	}
+	if t := openAPIType(route.Response); t != nil {
+		operation.Responses["200"] = &OpenAPIResponse{
+			Description: http.StatusText(http.StatusOK),
+			Content:     map[string]*OpenAPIMediaType{gin.MIMEJSON: {Schema: o.generator.schema(t)}},
+		}
+	} else {
+		operation.Responses["204"] = &OpenAPIResponse{Description: http.StatusText(http.StatusNoContent)}
+	}
+
+	if o.doc.Paths[path] == nil {
+		o.doc.Paths[path] = map[string]*OpenAPIOperation{}
+	}
+	o.doc.Paths[path][strings.ToLower(method)] = operation
+}
+
+func (o *OpenAPI) addRequest(operation *OpenAPIOperation, method string, t reflect.Type) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		operation.RequestBody = &OpenAPIRequestBody{
+			Required: true,
+			Content:  map[string]*OpenAPIMediaType{gin.MIMEJSON: {Schema: o.generator.schema(t)}},
+		}
+		return
+	}
+	hasBody := method != http.MethodGet && method != http.MethodHead && method != http.MethodDelete
+
+	jsonBody := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	formBody := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, field := range tagFields(t, "uri", "header", "form", "json") {
+		tag := field.Field.Tag
+		formTag, isForm := tag.Lookup("form")
+		jsonTag, isJSON := tag.Lookup("json")
+		switch {
+		case tag.Get("uri") != "":
+			operation.Parameters = append(operation.Parameters, o.parameter(field, "path"))
+		case tag.Get("header") != "":
+			operation.Parameters = append(operation.Parameters, o.parameter(field, "header"))
+		case !hasBody:
+			if formTag == "-" {
+				continue
+			}
+			operation.Parameters = append(operation.Parameters, o.parameter(field, "query"))
+		default:
+			if isForm && formTag != "-" {
+				addProperty(formBody, o.generator, tagFieldOf(field, "form"))
+			}
+			if (isJSON && jsonTag != "-") || !isForm {
+				addProperty(jsonBody, o.generator, tagFieldOf(field, "json"))
+			}
+		}
+	}
+	if !hasBody || (len(jsonBody.Properties) == 0 && len(formBody.Properties) == 0) {
+		return
+	}
+	operation.RequestBody = &OpenAPIRequestBody{Required: true, Content: map[string]*OpenAPIMediaType{}}
+	if len(jsonBody.Properties) > 0 {
+		operation.RequestBody.Content[gin.MIMEJSON] = &OpenAPIMediaType{Schema: jsonBody}
+	}
+	if len(formBody.Properties) > 0 {
+		operation.RequestBody.Content[gin.MIMEPOSTForm] = &OpenAPIMediaType{Schema: formBody}
+		operation.RequestBody.Content[gin.MIMEMultipartPOSTForm] = &OpenAPIMediaType{Schema: formBody}
+	}
+}
+
+// parameter the parameter of field, path parameter is always required.
+func (o *OpenAPI) parameter(field tagField, in string) *OpenAPIParameter {
+	key := map[string]string{"path": "uri", "header": "header", "query": "form"}[in]
+	field = tagFieldOf(field, key)
+	// parameter can not be null, so only the required and validate keywords of fieldSchema are used
+	_, required := o.generator.fieldSchema(field, true)
+	schema := o.generator.schema(field.Field.Type)
+	applyValidateRules(schema, validateRules(field.Field), derefType(field.Field.Type))
+	parameter := &OpenAPIParameter{
+		Name:     field.Name,
+		In:       in,
+		Required: required || in == "path",
+		Schema:   schema,
+	}
+	if in == "query" && isArraySchema(schema) {
+		explode := false
+		switch field.Field.Tag.Get("collection_format") {
+		case "csv":
+			parameter.Style = "form"
+		case "ssv":
+			parameter.Style = "spaceDelimited"
+		case "pipes":
+			parameter.Style = "pipeDelimited"
+		default:
+			parameter.Style, explode = "form", true
+		}
+		parameter.Explode = &explode
+	}
+	return parameter
+}
+
+// tagFieldOf rename the field by the tag key.
+func tagFieldOf(field tagField, key string) tagField {
+	name, options, _ := fieldTagName(field.Field, []string{key})
+	if name == "" {
+		name = field.Field.Name
+	}
+	field.Name, field.Options = name, options
+	return field
+}
+
+func addProperty(schema *Schema, generator *schemaGenerator, field tagField) {
+	fieldSchema, required := generator.fieldSchema(field, field.hasTagOption("omitempty") || field.hasTagOption("omitzero"))
+	schema.Properties[field.Name] = fieldSchema
+	if required {
+		schema.Required = append(schema.Required, field.Name)
+	}
+}
+
+func isArraySchema(schema *Schema) bool {
+	return schema.Type == "array"
+}
+
+func openAPIType(v any) reflect.Type {
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case reflect.Type:
+		return t
+	default:
+		return reflect.TypeOf(v)
+	}
+}
+
+// Document return the generated document.
+func (o *OpenAPI) Document() *OpenAPIDocument {
+	return o.doc
+}
+
+// JSON marshal the document as json.
+func (o *OpenAPI) JSON() ([]byte, error) {
+	return json.MarshalIndent(o.doc, "", "  ")
+}
+
+// YAML marshal the document as yaml, the order of keys is same as json.
+func (o *OpenAPI) YAML() ([]byte, error) {
+	b, err := json.Marshal(o.doc)
+	if err != nil {
+		return nil, err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(b, &node); err != nil {
+		return nil, err
+	}
+	resetYAMLStyle(&node)
+	return yaml.Marshal(&node)
+}
+
+// resetYAMLStyle use block style instead of the flow style of json.
+func resetYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetYAMLStyle(child)
+	}
+}
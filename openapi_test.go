@@ -0,0 +1,117 @@
+package mox
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+type OpenAPIUser struct {
+	ID       int64              `json:"id"`
+	Name     string             `json:"name"`
+	Nick     mo.Option[string]  `json:"nick_name,omitempty"`
+	Email    mo.Option[*string] `json:"email"`
+	Friends  []OpenAPIUser      `json:"friends,omitempty"`
+	internal string
+}
+
+type OpenAPIListUserReq struct {
+	Name   mo.Option[string]  `form:"name"`
+	Age    mo.Option[int]     `form:"age" validate:"present,min=1"`
+	Sort   string             `form:"sort" binding:"omitempty,oneof=asc desc"`
+	IDs    mo.Option[[]int64] `form:"ids"`
+	Tags   []string           `form:"tags" collection_format:"csv"`
+	Token  string             `header:"X-Token" binding:"required"`
+	Ignore string             `form:"-"`
+}
+
+type OpenAPIUpdateUserReq struct {
+	ID   int64             `uri:"id"`
+	Name mo.Option[string] `json:"name" form:"name" validate:"notnil"`
+	Nick mo.Option[string] `json:"nick_name,omitempty"`
+}
+
+func TestOpenAPI(t *testing.T) {
+	engine := gin.New()
+	engine.GET("/users", func(c *gin.Context) {})
+	engine.PATCH("/users/:id", func(c *gin.Context) {})
+	engine.DELETE("/users/:id", func(c *gin.Context) {})
+	engine.GET("/health", func(c *gin.Context) {})
+
+	api := NewOpenAPI("user", "1.0.0")
+	api.AddGinRoutes(engine.Routes(), map[string]OpenAPIRoute{
+		"GET /users":       {OperationID: "listUser", Request: OpenAPIListUserReq{}, Response: []OpenAPIUser{}},
+		"PATCH /users/:id": {Request: &OpenAPIUpdateUserReq{}, Response: OpenAPIUser{}},
+		"DELETE /users/:id": {Request: struct {
+			ID int64 `uri:"id"`
+		}{}},
+	})
+	doc := api.Document()
+	require.Equal(t, "3.1.0", doc.OpenAPI)
+	require.Len(t, doc.Paths, 2)
+
+	list := doc.Paths["/users"]["get"]
+	require.Equal(t, "listUser", list.OperationID)
+	require.Nil(t, list.RequestBody)
+	b, err := json.Marshal(list.Parameters)
+	require.NoError(t, err)
+	require.JSONEq(t, `[
+		{"name": "name", "in": "query", "schema": {"type": "string"}},
+		{"name": "age", "in": "query", "required": true, "schema": {"type": "integer", "format": "int64", "minimum": 1}},
+		{"name": "sort", "in": "query", "schema": {"type": "string", "enum": ["asc", "desc"]}},
+		{"name": "ids", "in": "query", "style": "form", "explode": true, "schema": {"type": "array", "items": {"type": "integer", "format": "int64"}}},
+		{"name": "tags", "in": "query", "style": "form", "explode": false, "schema": {"type": "array", "items": {"type": "string"}}},
+		{"name": "X-Token", "in": "header", "required": true, "schema": {"type": "string"}}
+	]`, string(b))
+	b, err = json.Marshal(list.Responses)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"200": {"description": "OK", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/OpenAPIUser"}}}}}}`, string(b))
+
+	update := doc.Paths["/users/{id}"]["patch"]
+	b, err = json.Marshal(update)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer", "format": "int64"}}],
+		"requestBody": {"required": true, "content": {
+			"application/json": {"schema": {"type": "object", "properties": {"name": {"type": "string"}, "nick_name": {"type": "string"}}, "required": ["name"]}},
+			"application/x-www-form-urlencoded": {"schema": {"type": "object", "properties": {"name": {"type": "string"}}, "required": ["name"]}},
+			"multipart/form-data": {"schema": {"type": "object", "properties": {"name": {"type": "string"}}, "required": ["name"]}}
+		}},
+		"responses": {"200": {"description": "OK", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/OpenAPIUser"}}}}}
+	}`, string(b))
+	require.Contains(t, doc.Paths["/users/{id}"]["delete"].Responses, "204")
+
+	b, err = json.Marshal(doc.Components.Schemas)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"OpenAPIUser": {
+		"type": "object",
+		"properties": {
+			"id": {"type": "integer", "format": "int64"},
+			"name": {"type": "string"},
+			"nick_name": {"type": "string"},
+			"email": {"type": ["string", "null"]},
+			"friends": {"type": "array", "items": {"$ref": "#/components/schemas/OpenAPIUser"}}
+		},
+		"required": ["id", "name"]
+	}}`, string(b))
+
+	jsonDoc, err := api.JSON()
+	require.NoError(t, err)
+	yamlDoc, err := api.YAML()
+	require.NoError(t, err)
+	require.Contains(t, string(yamlDoc), "openapi: 3.1.0\n")
+	require.Contains(t, string(yamlDoc), "\"200\":\n")
+	var fromJSON, fromYAML map[string]any
+	require.NoError(t, json.Unmarshal(jsonDoc, &fromJSON))
+	require.NoError(t, yaml.Unmarshal(yamlDoc, &fromYAML))
+	require.Equal(t, fromJSON["paths"].(map[string]any)["/users"].(map[string]any)["get"].(map[string]any)["operationId"],
+		fromYAML["paths"].(map[string]any)["/users"].(map[string]any)["get"].(map[string]any)["operationId"])
+
+	api.AddRoute(OpenAPIRoute{Method: http.MethodPost, Path: "/files/*path", Request: []string{}})
+	require.NotNil(t, api.Document().Paths["/files/{path}"]["post"].RequestBody)
+}
@@ -0,0 +1,52 @@
+package mox
+
+import (
+	"encoding/json"
+
+	"github.com/samber/mo"
+)
+
+// Option a mo.Option which IsZero only when absent, so it works with go1.24 `omitzero` of encoding/json.
+//
+//	mo.Option.IsZero is true for Some(zero value), so `omitzero` will also omit Some("").
+type Option[T any] struct {
+	mo.Option[T]
+}
+
+// Some builds an Option when value is present.
+func Some[T any](value T) Option[T] {
+	return Option[T]{Option: mo.Some(value)}
+}
+
+// None builds an Option when value is absent.
+func None[T any]() Option[T] {
+	return Option[T]{Option: mo.None[T]()}
+}
+
+// FromOption wrap mo.Option.
+func FromOption[T any](o mo.Option[T]) Option[T] {
+	return Option[T]{Option: o}
+}
+
+// IsZero for `omitzero`, only absent is zero.
+func (o Option[T]) IsZero() bool {
+	return o.IsAbsent()
+}
+
+// MarshalJSON absent as null, else the value.
+func (o Option[T]) MarshalJSON() ([]byte, error) {
+	if o.IsAbsent() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.MustGet())
+}
+
+// UnmarshalJSON same as mo.Option, when the key exists, it is present even the value is null.
+func (o *Option[T]) UnmarshalJSON(b []byte) error {
+	var value T
+	if err := json.Unmarshal(b, &value); err != nil {
+		return err
+	}
+	o.Option = mo.Some(value)
+	return nil
+}
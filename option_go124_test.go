@@ -0,0 +1,31 @@
+//go:build go1.24
+
+package mox
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionOmitZero(t *testing.T) {
+	type User struct {
+		Name   Option[string]    `json:"name,omitzero"`
+		MoName mo.Option[string] `json:"mo_name,omitzero"`
+	}
+	b, err := json.Marshal(User{Name: Some(""), MoName: mo.Some("")})
+	require.NoError(t, err)
+	// mo.Option treat Some("") as zero.
+	require.Equal(t, `{"name":""}`, string(b))
+
+	b, err = json.Marshal(User{})
+	require.NoError(t, err)
+	require.Equal(t, `{}`, string(b))
+
+	var user User
+	require.NoError(t, json.Unmarshal([]byte(`{"name":""}`), &user))
+	require.Equal(t, Some(""), user.Name)
+	require.Equal(t, mo.None[string](), user.MoName)
+}
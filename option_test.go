@@ -0,0 +1,70 @@
+package mox
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionJson(t *testing.T) {
+	type User struct {
+		Name Option[string] `json:"name"`
+		Age  Option[int]    `json:"age"`
+	}
+	b, err := json.Marshal(User{Name: Some("")})
+	require.NoError(t, err)
+	require.Equal(t, `{"name":"","age":null}`, string(b))
+
+	var user User
+	require.NoError(t, json.Unmarshal([]byte(`{"name":"sb"}`), &user))
+	require.Equal(t, Some("sb"), user.Name)
+	require.Equal(t, None[int](), user.Age)
+
+	// the key exists, so it is present
+	require.NoError(t, json.Unmarshal([]byte(`{"age":null}`), &user))
+	require.Equal(t, Some(0), user.Age)
+
+	require.True(t, None[string]().IsZero())
+	require.False(t, Some("").IsZero())
+	require.Equal(t, mo.Some(1), FromOption(mo.Some(1)).Option)
+}
+
+func TestOptionJsoniter(t *testing.T) {
+	type User struct {
+		Name Option[string] `json:"name,omitempty"`
+	}
+	jsoniterApi := jsoniter.Config{}.Froze()
+	jsoniterApi.RegisterExtension(&OptionExtension{})
+	b, err := jsoniterApi.Marshal(User{Name: Some("")})
+	require.NoError(t, err)
+	require.Equal(t, `{"name":""}`, string(b))
+	b, err = jsoniterApi.Marshal(User{})
+	require.NoError(t, err)
+	require.Equal(t, `{}`, string(b))
+}
+
+func TestOptionBindAndValidate(t *testing.T) {
+	type Dto struct {
+		Name Option[string]   `form:"name" validate:"present,min=2"`
+		IDs  Option[[]int]    `form:"ids"`
+		Tags Option[[]string] `form:"tags"`
+	}
+	var dto Dto
+	req := httptest.NewRequest("GET", "/?name=sb&ids=1&ids=2", nil)
+	require.NoError(t, OptionQueryBinding.Bind(req, &dto))
+	require.Equal(t, Some("sb"), dto.Name)
+	require.Equal(t, Some([]int{1, 2}), dto.IDs)
+	require.Equal(t, None[[]string](), dto.Tags)
+
+	validate := validator.New()
+	require.NoError(t, RegisterGPValidatorPresent(validate))
+	RegisterGPVUnwrapOptionTypeFunc(validate)
+	require.NoError(t, validate.Struct(&dto))
+	require.ErrorContains(t, validate.Struct(&Dto{Name: Some("s")}), "failed on the 'min' tag")
+	require.ErrorContains(t, validate.Struct(&Dto{}), "failed on the 'present' tag")
+}
@@ -0,0 +1,294 @@
+package mox
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	ErrPatchTarget       = errors.New("patch target must be a non-nil pointer")
+	ErrPatchTypeMismatch = errors.New("patch type mismatch")
+)
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// ApplyMergePatch apply JSON Merge Patch (RFC 7386) to dst, fields are matched by json tag name.
+//   - null: set option to None, other field to zero value, delete the key of map.
+//   - object: merge into struct, map, pointer and the value of option recursively.
+//   - other: replace the value, option will be Some(value).
+func ApplyMergePatch(dst any, patch []byte) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("%w: %T", ErrPatchTarget, dst)
+	}
+	return mergePatch(v.Elem(), bytes.TrimSpace(patch), "")
+}
+
+func mergePatch(v reflect.Value, patch []byte, path string) error {
+	if info, ok := OptionOf(v.Type()); ok {
+		if isJSONNull(patch) {
+			info.unset(v)
+			return nil
+		}
+		value := reflect.New(info.Elem).Elem()
+		if current, ok := info.get(v); ok {
+			value.Set(current)
+		}
+		if err := mergePatch(value, patch, path); err != nil {
+			return err
+		}
+		info.set(v, value)
+		return nil
+	}
+	if isJSONNull(patch) {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	if !isJSONObject(patch) || reflect.PointerTo(v.Type()).Implements(jsonUnmarshalerType) {
+		return unmarshalPatch(v, patch, path)
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return mergePatch(v.Elem(), patch, path)
+	case reflect.Struct:
+		var members map[string]json.RawMessage
+		if err := json.Unmarshal(patch, &members); err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrPatchTypeMismatch, patchPath(path), err)
+		}
+		fields := tagFields(v.Type(), "json")
+		for name, member := range members {
+			field, ok := lookupTagField(fields, name)
+			if !ok {
+				continue
+			}
+			if err := mergePatch(v.FieldByIndex(field.Index), member, path+"/"+name); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return unmarshalPatch(v, patch, path)
+		}
+		var members map[string]json.RawMessage
+		if err := json.Unmarshal(patch, &members); err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrPatchTypeMismatch, patchPath(path), err)
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(v.Type(), len(members)))
+		}
+		for name, member := range members {
+			key := reflect.ValueOf(name).Convert(v.Type().Key())
+			if isJSONNull(member) {
+				v.SetMapIndex(key, reflect.Value{})
+				continue
+			}
+			value := reflect.New(v.Type().Elem()).Elem()
+			if current := v.MapIndex(key); current.IsValid() {
+				value.Set(current)
+			}
+			if err := mergePatch(value, member, path+"/"+name); err != nil {
+				return err
+			}
+			v.SetMapIndex(key, value)
+		}
+		return nil
+	case reflect.Interface:
+		var patchValue any
+		if err := json.Unmarshal(patch, &patchValue); err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrPatchTypeMismatch, patchPath(path), err)
+		}
+		var current any
+		if !v.IsNil() {
+			current = v.Interface()
+		}
+		merged := mergePatchAny(current, patchValue)
+		if merged == nil {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		if !reflect.TypeOf(merged).AssignableTo(v.Type()) {
+			return fmt.Errorf("%w: %s: can not assign %T to %s", ErrPatchTypeMismatch, patchPath(path), merged, v.Type())
+		}
+		v.Set(reflect.ValueOf(merged))
+		return nil
+	default:
+		return fmt.Errorf("%w: %s: can not merge object into %s", ErrPatchTypeMismatch, patchPath(path), v.Type())
+	}
+}
+
+// mergePatchAny the MergePatch function of RFC 7386 for the decoded json value.
+func mergePatchAny(target any, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = make(map[string]any, len(patchObject))
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+		} else {
+			targetObject[name] = mergePatchAny(targetObject[name], value)
+		}
+	}
+	return targetObject
+}
+
+func unmarshalPatch(v reflect.Value, patch []byte, path string) error {
+	value := reflect.New(v.Type())
+	if err := json.Unmarshal(patch, value.Interface()); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrPatchTypeMismatch, patchPath(path), err)
+	}
+	v.Set(value.Elem())
+	return nil
+}
+
+func patchPath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+func isJSONNull(b []byte) bool {
+	return string(bytes.TrimSpace(b)) == "null"
+}
+
+func isJSONObject(b []byte) bool {
+	b = bytes.TrimSpace(b)
+	return len(b) > 0 && b[0] == '{'
+}
+
+// ApplyPresent copy the present option fields of patch to dst, the nested struct will be applied recursively.
+// fields are matched by field name, then by json tag name.
+//   - dst field is option: set Some(value).
+//   - dst field is pointer: set a new pointer to value.
+//   - else: set value.
+func ApplyPresent(dst any, patch any) error {
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() {
+		return fmt.Errorf("%w: %T", ErrPatchTarget, dst)
+	}
+	dv = dv.Elem()
+	pv := reflect.ValueOf(patch)
+	if pv.Kind() == reflect.Ptr {
+		if pv.IsNil() {
+			return nil
+		}
+		pv = pv.Elem()
+	}
+	if dv.Kind() != reflect.Struct || pv.Kind() != reflect.Struct {
+		return fmt.Errorf("%w: dst=%s, patch=%s", ErrOnlyStruct, dv.Kind(), pv.Kind())
+	}
+	return applyPresent(dv, pv, "")
+}
+
+func applyPresent(dst reflect.Value, patch reflect.Value, path string) error {
+	dstFields := tagFields(dst.Type(), "json")
+	for _, patchField := range tagFields(patch.Type(), "json") {
+		dstField, ok := matchPresentField(dstFields, patchField)
+		if !ok {
+			continue
+		}
+		fieldPath := patchField.Field.Name
+		if path != "" {
+			fieldPath = path + "." + fieldPath
+		}
+		patchValue := patch.FieldByIndex(patchField.Index)
+		dstValue := dst.FieldByIndex(dstField.Index)
+
+		if info, ok := OptionOf(patchField.Field.Type); ok {
+			value, ok := info.get(patchValue)
+			if !ok {
+				continue
+			}
+			if err := assignPresent(dstValue, value, fieldPath); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if patchValue.Kind() == reflect.Ptr {
+			if patchValue.IsNil() {
+				continue
+			}
+			patchValue = patchValue.Elem()
+		}
+		if patchValue.Kind() != reflect.Struct {
+			// only option can express present
+			continue
+		}
+		if dstValue.Kind() == reflect.Ptr {
+			if dstValue.IsNil() {
+				dstValue.Set(reflect.New(dstValue.Type().Elem()))
+			}
+			dstValue = dstValue.Elem()
+		}
+		if dstValue.Kind() != reflect.Struct || IsOption(dstValue.Type()) {
+			return fmt.Errorf("%w: %s: can not apply %s to %s", ErrPatchTypeMismatch, fieldPath, patchValue.Type(), dstValue.Type())
+		}
+		if err := applyPresent(dstValue, patchValue, fieldPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func matchPresentField(fields []tagField, field tagField) (tagField, bool) {
+	for _, f := range fields {
+		if f.Field.Name == field.Field.Name {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if f.Name == field.Name {
+			return f, true
+		}
+	}
+	return tagField{}, false
+}
+
+func assignPresent(dst reflect.Value, value reflect.Value, path string) error {
+	if info, ok := OptionOf(dst.Type()); ok {
+		converted, ok := convertPresent(value, info.Elem)
+		if !ok {
+			return fmt.Errorf("%w: %s: can not assign %s to %s", ErrPatchTypeMismatch, path, value.Type(), dst.Type())
+		}
+		info.set(dst, converted)
+		return nil
+	}
+	if converted, ok := convertPresent(value, dst.Type()); ok {
+		dst.Set(converted)
+		return nil
+	}
+	if dst.Kind() == reflect.Ptr {
+		if converted, ok := convertPresent(value, dst.Type().Elem()); ok {
+			ptr := reflect.New(dst.Type().Elem())
+			ptr.Elem().Set(converted)
+			dst.Set(ptr)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s: can not assign %s to %s", ErrPatchTypeMismatch, path, value.Type(), dst.Type())
+}
+
+// convertPresent only allow assignable, or convertible with the same kind, like named type.
+func convertPresent(value reflect.Value, typ reflect.Type) (reflect.Value, bool) {
+	if value.Type().AssignableTo(typ) {
+		return value, true
+	}
+	if value.Kind() == typ.Kind() && value.Type().ConvertibleTo(typ) {
+		return value.Convert(typ), true
+	}
+	return reflect.Value{}, false
+}
@@ -0,0 +1,107 @@
+package mox
+
+import (
+	"testing"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+)
+
+type PatchAddress struct {
+	City   string            `json:"city"`
+	Street mo.Option[string] `json:"street"`
+}
+
+type PatchEntity struct {
+	Name      string            `json:"name"`
+	Nick      mo.Option[string] `json:"nick_name"`
+	Age       *int              `json:"age"`
+	Tags      []string          `json:"tags"`
+	Labels    map[string]string `json:"labels"`
+	Address   PatchAddress      `json:"address"`
+	Extra     any               `json:"extra"`
+	OptionAdr mo.Option[PatchAddress]
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	age := 18
+	entity := PatchEntity{
+		Name:    "sb",
+		Nick:    mo.Some("nick"),
+		Age:     &age,
+		Tags:    []string{"a"},
+		Labels:  map[string]string{"a": "1", "b": "2"},
+		Address: PatchAddress{City: "c", Street: mo.Some("s")},
+		Extra:   map[string]any{"a": 1.0, "b": 2.0},
+	}
+	require.NoError(t, ApplyMergePatch(&entity, []byte(`{
+		"name": "sb2",
+		"nick_name": null,
+		"age": 20,
+		"tags": ["b", "c"],
+		"labels": {"a": null, "c": "3"},
+		"address": {"street": null},
+		"extra": {"a": null, "c": {"d": 1}},
+		"optionadr": {"city": "oc"},
+		"unknown": 1
+	}`)))
+	age = 20
+	require.Equal(t, PatchEntity{
+		Name:      "sb2",
+		Nick:      mo.None[string](),
+		Age:       &age,
+		Tags:      []string{"b", "c"},
+		Labels:    map[string]string{"b": "2", "c": "3"},
+		Address:   PatchAddress{City: "c"},
+		Extra:     map[string]any{"b": 2.0, "c": map[string]any{"d": 1.0}},
+		OptionAdr: mo.Some(PatchAddress{City: "oc"}),
+	}, entity)
+
+	require.NoError(t, ApplyMergePatch(&entity, []byte(`{"age": null, "address": {"street": "s2"}}`)))
+	require.Nil(t, entity.Age)
+	require.Equal(t, PatchAddress{City: "c", Street: mo.Some("s2")}, entity.Address)
+
+	require.ErrorIs(t, ApplyMergePatch(&entity, []byte(`{"address": {"city": 1}}`)), ErrPatchTypeMismatch)
+	require.ErrorContains(t, ApplyMergePatch(&entity, []byte(`{"address": {"city": 1}}`)), "/address/city")
+	require.ErrorIs(t, ApplyMergePatch(&entity, []byte(`{"tags": {"a": 1}}`)), ErrPatchTypeMismatch)
+	require.ErrorIs(t, ApplyMergePatch(entity, []byte(`{}`)), ErrPatchTarget)
+}
+
+func TestApplyPresent(t *testing.T) {
+	type Name string
+	type AddressPatch struct {
+		City mo.Option[string] `json:"city"`
+	}
+	type EntityPatch struct {
+		Name    mo.Option[Name]   `json:"name"`
+		Nick    mo.Option[string] `json:"nick_name"`
+		Years   mo.Option[int]    `json:"age"`
+		Street  mo.Option[string] `json:"street"`
+		Address *AddressPatch     `json:"address"`
+	}
+	type Entity struct {
+		Name    string
+		Nick    mo.Option[string] `json:"nick_name"`
+		Age     *int              `json:"age"`
+		Street  string            `json:"street"`
+		Address PatchAddress      `json:"address"`
+	}
+	entity := Entity{Name: "sb", Nick: mo.Some("nick"), Street: "s", Address: PatchAddress{City: "c"}}
+	require.NoError(t, ApplyPresent(&entity, EntityPatch{
+		Name:    mo.Some[Name]("sb2"),
+		Years:   mo.Some(18),
+		Address: &AddressPatch{City: mo.Some("c2")},
+	}))
+	age := 18
+	require.Equal(t, Entity{Name: "sb2", Nick: mo.Some("nick"), Age: &age, Street: "s", Address: PatchAddress{City: "c2"}}, entity)
+
+	require.NoError(t, ApplyPresent(&entity, &EntityPatch{Nick: mo.Some("nick2")}))
+	require.Equal(t, mo.Some("nick2"), entity.Nick)
+
+	require.ErrorIs(t, ApplyPresent(&entity, struct {
+		Age mo.Option[string] `json:"age"`
+	}{Age: mo.Some("18")}), ErrPatchTypeMismatch)
+	require.ErrorIs(t, ApplyPresent(&entity, struct {
+		Name AddressPatch
+	}{}), ErrPatchTypeMismatch)
+}
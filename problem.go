@@ -0,0 +1,175 @@
+package mox
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// MIMEProblemJSON the content type of RFC 7807.
+const MIMEProblemJSON = "application/problem+json"
+
+// ProblemCodes the code of validate tag in ProblemError, the tag is used as code if not found.
+var ProblemCodes = map[string]string{
+	"present": "missing",
+	"notnil":  "null",
+}
+
+const (
+	// ProblemCodeInvalidValue the value can not be parsed, like "a" for int.
+	ProblemCodeInvalidValue = "invalid_value"
+	// ProblemCodeUnsupported the type of field is not supported by binding.
+	ProblemCodeUnsupported = "unsupported"
+)
+
+// ProblemDetails the problem details of RFC 7807.
+type ProblemDetails struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Errors the invalid fields of binding and validation.
+	Errors []ProblemError `json:"errors,omitempty"`
+}
+
+// ProblemError the invalid field.
+type ProblemError struct {
+	// Field the path of field, like "address.city", "[0].name".
+	Field string `json:"field"`
+	// Tag the validate tag, empty for binding error.
+	Tag   string `json:"tag,omitempty"`
+	Param string `json:"param,omitempty"`
+	// Code the machine-readable code, see ProblemCodes.
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// NewProblem convert err to ProblemDetails.
+//   - 400 for ErrBind, BindFieldError, validator.ValidationErrors and binding.SliceValidationError, with Errors.
+//   - 500 for other errors, the detail is hidden.
+func NewProblem(err error) *ProblemDetails {
+	fieldErrors, ok := problemErrors(err)
+	status := http.StatusBadRequest
+	detail := err.Error()
+	if !ok && !errors.Is(err, ErrBind) {
+		status, detail = http.StatusInternalServerError, ""
+	}
+	return &ProblemDetails{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Errors: fieldErrors,
+	}
+}
+
+// Problem render err as application/problem+json by NewProblem, and abort.
+// can be used as the error handler of Handle, like Handle(fn, WithErrorHandler(Problem)).
+func Problem(c *gin.Context, err error) {
+	problem := NewProblem(err)
+	problem.Instance = c.Request.URL.Path
+	c.Header("Content-Type", MIMEProblemJSON)
+	c.AbortWithStatusJSON(problem.Status, problem)
+}
+
+// ProblemMiddleware render the last error of c.Errors by Problem if the response body is not written.
+// the status of c.AbortWithError, like c.MustBindWith, is kept unwritten until the end, so the bind error is rendered,
+// other errors are not rendered if the status is written by the handler, like c.AbortWithStatus(404).
+func ProblemMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writer := &problemWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+		if len(c.Errors) > 0 && writer.Size() < 0 {
+			last := c.Errors.Last()
+			if !writer.deferred || last.IsType(gin.ErrorTypeBind) {
+				Problem(c, last.Err)
+				return
+			}
+		}
+		if writer.deferred {
+			c.Writer.WriteHeaderNow()
+		}
+	}
+}
+
+// problemWriter defer WriteHeaderNow until the body is written or the end of ProblemMiddleware.
+type problemWriter struct {
+	gin.ResponseWriter
+	deferred bool
+}
+
+func (w *problemWriter) WriteHeaderNow() {
+	w.deferred = true
+}
+
+// problemErrors return false if err is not a binding or validation error.
+func problemErrors(err error) ([]ProblemError, bool) {
+	var validationErrors validator.ValidationErrors
+	var sliceErrors binding.SliceValidationError
+	var fieldError *BindFieldError
+	switch {
+	case errors.As(err, &validationErrors):
+		fieldErrors := make([]ProblemError, 0, len(validationErrors))
+		for _, fe := range validationErrors {
+			code, ok := ProblemCodes[fe.Tag()]
+			if !ok {
+				code = fe.Tag()
+			}
+			fieldErrors = append(fieldErrors, ProblemError{
+				Field:   problemField(fe.Namespace()),
+				Tag:     fe.Tag(),
+				Param:   fe.Param(),
+				Code:    code,
+				Message: fe.Error(),
+			})
+		}
+		return fieldErrors, true
+	case errors.As(err, &sliceErrors):
+		var fieldErrors []ProblemError
+		for i, e := range sliceErrors {
+			if e == nil {
+				continue
+			}
+			items, _ := problemErrors(e)
+			for _, item := range items {
+				item.Field = joinProblemField("["+strconv.Itoa(i)+"]", item.Field)
+				fieldErrors = append(fieldErrors, item)
+			}
+		}
+		return fieldErrors, true
+	case errors.As(err, &fieldError):
+		code := ProblemCodeInvalidValue
+		if errors.Is(err, ErrNotSupportKind) || errors.Is(err, ErrNotSupportOptionValueKind) {
+			code = ProblemCodeUnsupported
+		}
+		return []ProblemError{{
+			Field:   fieldError.Field,
+			Code:    code,
+			Message: fieldError.Err.Error(),
+		}}, true
+	}
+	return nil, false
+}
+
+// problemField remove the struct name of namespace, like "Dto.Address.City" to "Address.City".
+func problemField(namespace string) string {
+	if i := strings.IndexByte(namespace, '.'); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+func joinProblemField(prefix string, field string) string {
+	if field == "" || strings.HasPrefix(field, "[") {
+		return prefix + field
+	}
+	return prefix + "." + field
+}
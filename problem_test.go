@@ -0,0 +1,123 @@
+package mox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+)
+
+type ProblemAddress struct {
+	City string `binding:"max=3"`
+}
+
+type ProblemDto struct {
+	Age     mo.Option[int]    `form:"age" binding:"present"`
+	Name    mo.Option[string] `form:"name" binding:"notnil"`
+	Address ProblemAddress
+}
+
+func TestProblem(t *testing.T) {
+	defaultValidator := binding.Validator
+	defer func() { binding.Validator = defaultValidator }()
+	binding.Validator = NewValidator()
+
+	engine := gin.New()
+	engine.Use(ProblemMiddleware())
+	engine.GET("/problem", func(c *gin.Context) {
+		value := ProblemDto{Address: ProblemAddress{City: c.Query("city")}}
+		if err := c.ShouldBindWith(&value, OptionQueryBinding); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+	engine.GET("/must", func(c *gin.Context) {
+		var value ProblemDto
+		_ = c.MustBindWith(&value, OptionQueryBinding)
+	})
+	engine.GET("/not_found", func(c *gin.Context) {
+		_ = c.Error(errors.New("not found"))
+		c.AbortWithStatus(http.StatusNotFound)
+	})
+	engine.GET("/slice", func(c *gin.Context) {
+		_ = c.Error(binding.SliceValidationError{nil, binding.Validator.ValidateStruct(ProblemAddress{City: "abcd"})})
+	})
+	engine.GET("/internal", func(c *gin.Context) {
+		_ = c.Error(errors.New("db down"))
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := serve("/problem?age=a")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, MIMEProblemJSON, w.Header().Get("Content-Type"))
+	require.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Bad Request",
+		"status": 400,
+		"detail": "bind age: strconv.ParseInt: parsing \"a\": invalid syntax",
+		"instance": "/problem",
+		"errors": [{"field": "age", "code": "invalid_value", "message": "strconv.ParseInt: parsing \"a\": invalid syntax"}]
+	}`, w.Body.String())
+
+	// c.MustBindWith abort with 400 before the middleware
+	w = serve("/must?age=abc")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, MIMEProblemJSON, w.Header().Get("Content-Type"))
+	problem := decodeProblem(t, w)
+	require.Equal(t, "/must", problem.Instance)
+	require.Equal(t, []ProblemError{{Field: "age", Code: ProblemCodeInvalidValue, Message: problem.Errors[0].Message}}, problem.Errors)
+
+	w = serve("/not_found")
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Empty(t, w.Body.String())
+
+	w = serve("/problem?city=abcd")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	problem = decodeProblem(t, w)
+	require.Equal(t, []ProblemError{
+		{Field: "age", Tag: "present", Code: "missing", Message: problem.Errors[0].Message},
+		{Field: "name", Tag: "notnil", Code: "null", Message: problem.Errors[1].Message},
+		{Field: "Address.City", Tag: "max", Param: "3", Code: "max", Message: problem.Errors[2].Message},
+	}, problem.Errors)
+	require.Contains(t, problem.Errors[0].Message, "failed on the 'present' tag")
+
+	w = serve("/problem?age=1&name=sb")
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	w = serve("/slice")
+	problem = decodeProblem(t, w)
+	require.Len(t, problem.Errors, 1)
+	require.Equal(t, "[1].City", problem.Errors[0].Field)
+
+	w = serve("/internal")
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.JSONEq(t, `{"type": "about:blank", "title": "Internal Server Error", "status": 500, "instance": "/internal"}`, w.Body.String())
+
+	engine.GET("/handle", Handle(func(ctx context.Context, req ProblemDto) (ProblemDto, error) {
+		return req, nil
+	}, WithErrorHandler(Problem)))
+	w = serve("/handle?name=sb")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	problem = decodeProblem(t, w)
+	require.Len(t, problem.Errors, 1)
+	require.Equal(t, "age", problem.Errors[0].Field)
+}
+
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder) ProblemDetails {
+	var problem ProblemDetails
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	return problem
+}
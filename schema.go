@@ -0,0 +1,321 @@
+package mox
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema the JSON Schema (draft 2020-12), also used by OpenAPI 3.1.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	MinProperties        *int               `json:"minProperties,omitempty"`
+	MaxProperties        *int               `json:"maxProperties,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+}
+
+var (
+	timeType           = reflect.TypeOf(time.Time{})
+	jsonRawMessageType = reflect.TypeOf(json.RawMessage{})
+	schemaNameRegexp   = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+	oneofRegexp        = regexp.MustCompile(`'[^']*'|\S+`)
+)
+
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema generate the JSON Schema (draft 2020-12) of t, named structs are in $defs.
+// the schema describes the json encoded by OptionJSON (OptionExtension), which omit the absent option with omitempty,
+// encoding/json encode the absent mo.Option as null, which is rejected if the field is not nullable.
+//   - mo.Option is not required, and nullable when elem is pointer, without omitempty, or with tag `mox:"null"`.
+//   - the name of property is from json tag, omitempty and omitzero field is not required.
+//   - min, max, len, oneof, email of validate or binding tag are translated to keywords,
+//     present and notnil are required, and notnil is not nullable.
+func JSONSchema(t reflect.Type) *Schema {
+	g := newSchemaGenerator("#/$defs/")
+	schema := g.schema(t)
+	schema.Schema = JSONSchemaDraft
+	if len(g.defs) > 0 {
+		schema.Defs = g.defs
+	}
+	return schema
+}
+
+// schemaGenerator generate the schema of go type, the named struct is generated into defs and referenced by refPrefix.
+type schemaGenerator struct {
+	refPrefix string
+	defs      map[string]*Schema
+	names     map[reflect.Type]string
+}
+
+func newSchemaGenerator(refPrefix string) *schemaGenerator {
+	return &schemaGenerator{
+		refPrefix: refPrefix,
+		defs:      map[string]*Schema{},
+		names:     map[reflect.Type]string{},
+	}
+}
+
+// schema return the not null schema of t, option and pointer are unwrapped.
+func (g *schemaGenerator) schema(t reflect.Type) *Schema {
+	if info, ok := OptionOf(t); ok {
+		return g.schema(info.Elem)
+	}
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case jsonRawMessageType:
+		return &Schema{}
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.schema(t.Elem())
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.elemSchema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.elemSchema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name, ok := g.names[t]
+		if !ok {
+			name = g.defName(t)
+			g.names[t] = name
+			// placeholder for recursive type
+			g.defs[name] = &Schema{}
+			*g.defs[name] = *g.structSchema(t)
+		}
+		return &Schema{Ref: g.refPrefix + name}
+	default:
+		return &Schema{}
+	}
+}
+
+// elemSchema the schema of slice or map element, option and pointer are nullable.
+func (g *schemaGenerator) elemSchema(t reflect.Type) *Schema {
+	schema := g.schema(t)
+	if IsOption(t) || t.Kind() == reflect.Ptr {
+		return nullableSchema(schema)
+	}
+	return schema
+}
+
+// defName return the unique name of named type in defs.
+func (g *schemaGenerator) defName(t reflect.Type) string {
+	base := schemaNameRegexp.ReplaceAllString(t.Name(), "_")
+	base = strings.Trim(base, "_")
+	name := base
+	for i := 2; ; i++ {
+		if _, ok := g.defs[name]; !ok {
+			return name
+		}
+		name = base + "_" + strconv.Itoa(i)
+	}
+}
+
+// structSchema the object schema of struct by json tag.
+func (g *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, field := range tagFields(t, "json") {
+		fieldSchema, required := g.fieldSchema(field, field.hasTagOption("omitempty") || field.hasTagOption("omitzero"))
+		schema.Properties[field.Name] = fieldSchema
+		if required {
+			schema.Required = append(schema.Required, field.Name)
+		}
+	}
+	return schema
+}
+
+// fieldSchema return the schema of field and whether it is required, by the encoding of OptionJSON.
+//   - option: not required, nullable when elem is pointer, or can be encoded as null.
+//   - pointer: not required, nullable.
+//   - other: required unless omit.
+//   - required, present, notnil of validate or binding tag make it required, and notnil make it not nullable.
+func (g *schemaGenerator) fieldSchema(field tagField, omit bool) (*Schema, bool) {
+	schema := g.schema(field.Field.Type)
+	rules := validateRules(field.Field)
+
+	applyValidateRules(schema, rules, derefType(field.Field.Type))
+
+	required, nullable := !omit, false
+	if info, ok := OptionOf(field.Field.Type); ok {
+		required = false
+		nullable = info.Elem.Kind() == reflect.Ptr || !omit || hasMoxTag(field.Field, MoxTagNull)
+	} else if field.Field.Type.Kind() == reflect.Ptr {
+		required, nullable = false, true
+	}
+	if _, ok := rules["required"]; ok {
+		required = true
+	}
+	if _, ok := rules["present"]; ok {
+		required = true
+	}
+	if _, ok := rules["notnil"]; ok {
+		required, nullable = true, false
+	}
+	if nullable {
+		schema = nullableSchema(schema)
+	}
+	return schema, required
+}
+
+// applyValidateRules translate min, max, len, oneof, email to the keywords of schema, t is the unwrapped type.
+func applyValidateRules(schema *Schema, rules map[string]string, t reflect.Type) {
+	if schema.Ref != "" {
+		return
+	}
+	for _, name := range []string{"min", "max", "len"} {
+		param, ok := rules[name]
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			continue
+		}
+		var minimum, maximum **float64
+		var minCount, maxCount **int
+		switch schema.Type {
+		case "integer", "number":
+			minimum, maximum = &schema.Minimum, &schema.Maximum
+		case "string":
+			minCount, maxCount = &schema.MinLength, &schema.MaxLength
+		case "array":
+			minCount, maxCount = &schema.MinItems, &schema.MaxItems
+		case "object":
+			minCount, maxCount = &schema.MinProperties, &schema.MaxProperties
+		default:
+			continue
+		}
+		count := int(n)
+		if name != "max" {
+			if minimum != nil {
+				*minimum = &n
+			} else {
+				*minCount = &count
+			}
+		}
+		if name != "min" {
+			if maximum != nil {
+				*maximum = &n
+			} else {
+				*maxCount = &count
+			}
+		}
+	}
+	if param, ok := rules["oneof"]; ok {
+		for _, value := range oneofRegexp.FindAllString(param, -1) {
+			value = strings.Trim(value, "'")
+			switch t.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+					schema.Enum = append(schema.Enum, n)
+				}
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				if n, err := strconv.ParseUint(value, 10, 64); err == nil {
+					schema.Enum = append(schema.Enum, n)
+				}
+			case reflect.Float32, reflect.Float64:
+				if n, err := strconv.ParseFloat(value, 64); err == nil {
+					schema.Enum = append(schema.Enum, n)
+				}
+			default:
+				schema.Enum = append(schema.Enum, value)
+			}
+		}
+	}
+	if _, ok := rules["email"]; ok {
+		schema.Format = "email"
+	}
+}
+
+// derefType unwrap option and pointer.
+func derefType(t reflect.Type) reflect.Type {
+	for {
+		if info, ok := OptionOf(t); ok {
+			t = info.Elem
+		} else if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		} else {
+			return t
+		}
+	}
+}
+
+// nullableSchema allow null for the schema.
+func nullableSchema(schema *Schema) *Schema {
+	switch typ := schema.Type.(type) {
+	case string:
+		schema.Type = []string{typ, "null"}
+		return schema
+	case nil:
+		if schema.Ref == "" && len(schema.AnyOf) == 0 {
+			// any
+			return schema
+		}
+	}
+	return &Schema{AnyOf: []*Schema{schema, {Type: "null"}}}
+}
+
+// validateRules parse the rules of validate and binding tag, like {"min": "1", "required": ""}.
+func validateRules(field reflect.StructField) map[string]string {
+	rules := map[string]string{}
+	for _, key := range []string{"validate", "binding"} {
+		tag := field.Tag.Get(key)
+		if tag == "" || tag == "-" {
+			continue
+		}
+		for _, rule := range strings.Split(tag, ",") {
+			name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+			if name == "" || name == "dive" {
+				// the rules after dive are for elements
+				break
+			}
+			rules[name] = param
+		}
+	}
+	return rules
+}
+
+// hasMoxTag report whether the mox tag of field contains option.
+func hasMoxTag(field reflect.StructField, option string) bool {
+	for _, o := range strings.Split(field.Tag.Get("mox"), ",") {
+		if strings.TrimSpace(o) == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package mox
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+)
+
+type SchemaAddress struct {
+	City string            `json:"city" validate:"min=1,max=32"`
+	Zip  mo.Option[string] `json:"zip,omitempty" validate:"len=6"`
+}
+
+type SchemaUser struct {
+	ID        int64                    `json:"id" validate:"min=1"`
+	Name      mo.Option[string]        `json:"name,omitempty" validate:"present,min=2"`
+	Email     mo.Option[string]        `json:"email" validate:"notnil,email"`
+	Nick      mo.Option[*string]       `json:"nick,omitempty"`
+	Role      string                   `json:"role,omitempty" validate:"oneof=admin 'super user'"`
+	Level     mo.Option[int]           `json:"level,omitempty" validate:"oneof=1 2 3"`
+	Tags      []string                 `json:"tags" validate:"max=3,dive,min=1"`
+	Address   mo.Option[SchemaAddress] `json:"address,omitempty"`
+	Parent    *SchemaUser              `json:"parent,omitempty"`
+	CreatedAt time.Time                `json:"created_at"`
+	Secret    string                   `json:"-"`
+}
+
+func TestJSONSchema(t *testing.T) {
+	b, err := json.Marshal(JSONSchema(reflect.TypeOf(SchemaUser{})))
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$ref": "#/$defs/SchemaUser",
+		"$defs": {
+			"SchemaAddress": {
+				"type": "object",
+				"properties": {
+					"city": {"type": "string", "minLength": 1, "maxLength": 32},
+					"zip": {"type": "string", "minLength": 6, "maxLength": 6}
+				},
+				"required": ["city"]
+			},
+			"SchemaUser": {
+				"type": "object",
+				"properties": {
+					"id": {"type": "integer", "format": "int64", "minimum": 1},
+					"name": {"type": "string", "minLength": 2},
+					"email": {"type": "string", "format": "email"},
+					"nick": {"type": ["string", "null"]},
+					"role": {"type": "string", "enum": ["admin", "super user"]},
+					"level": {"type": "integer", "format": "int64", "enum": [1, 2, 3]},
+					"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 3},
+					"address": {"$ref": "#/$defs/SchemaAddress"},
+					"parent": {"anyOf": [{"$ref": "#/$defs/SchemaUser"}, {"type": "null"}]},
+					"created_at": {"type": "string", "format": "date-time"}
+				},
+				"required": ["id", "name", "email", "tags", "created_at"]
+			}
+		}
+	}`, string(b))
+
+	b, err = json.Marshal(JSONSchema(reflect.TypeOf([]mo.Option[int]{})))
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "array",
+		"items": {"type": ["integer", "null"], "format": "int64"}
+	}`, string(b))
+}
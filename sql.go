@@ -0,0 +1,252 @@
+package mox
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrNoUpdateColumn = errors.New("no present option to update")
+)
+
+// Dialect the placeholder style of sql.
+type Dialect interface {
+	// Placeholder return the placeholder of the index-th (start from 1) argument.
+	Placeholder(index int) string
+}
+
+type questionDialect struct{}
+
+func (questionDialect) Placeholder(int) string {
+	return "?"
+}
+
+type dollarDialect struct{}
+
+func (dollarDialect) Placeholder(index int) string {
+	return "$" + strconv.Itoa(index)
+}
+
+var (
+	// MySQL placeholder is ?
+	MySQL Dialect = questionDialect{}
+	// SQLite placeholder is ?
+	SQLite Dialect = questionDialect{}
+	// PostgreSQL placeholder is $n
+	PostgreSQL Dialect = dollarDialect{}
+)
+
+// UpdateSet build the fragment of `UPDATE ... SET` from present options, the column name is from db tag.
+//   - the field without db tag or not option is ignored.
+//   - present nil value, like mo.Some[*string](nil), emit `col = NULL`.
+//   - return ErrNoUpdateColumn if no option is present.
+//
+// Example: `name = ?, deleted_at = NULL` with args ["sb"].
+func UpdateSet(obj any, dialect Dialect) (string, []any, error) {
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil, fmt.Errorf("%w: %T", ErrOnlyStruct, obj)
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return "", nil, fmt.Errorf("%w: kind=%s", ErrOnlyStruct, v.Kind())
+	}
+
+	var sets []string
+	var args []any
+	for _, field := range tagFields(v.Type(), "db") {
+		if _, ok := field.Field.Tag.Lookup("db"); !ok {
+			continue
+		}
+		info, ok := OptionOf(field.Field.Type)
+		if !ok {
+			continue
+		}
+		value, ok := info.get(v.FieldByIndex(field.Index))
+		if !ok {
+			continue
+		}
+		if isNilValue(value) {
+			sets = append(sets, field.Name+" = NULL")
+			continue
+		}
+		args = append(args, value.Interface())
+		sets = append(sets, field.Name+" = "+dialect.Placeholder(len(args)))
+	}
+	if len(sets) == 0 {
+		return "", nil, fmt.Errorf("%w: %s", ErrNoUpdateColumn, v.Type())
+	}
+	return strings.Join(sets, ", "), args, nil
+}
+
+// isNilValue report whether v is a nil pointer, interface, slice or map.
+func isNilValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
+
+// whereOperators the sql operator of op tag.
+var whereOperators = map[string]string{
+	"eq":   "=",
+	"ne":   "<>",
+	"gt":   ">",
+	"gte":  ">=",
+	"lt":   "<",
+	"lte":  "<=",
+	"like": "LIKE",
+	"in":   "IN",
+	"nin":  "NOT IN",
+}
+
+// Where build the conditions of WHERE from the filter dto, placeholder is ?, see WhereDialect.
+func Where(filter any) (string, []any, error) {
+	return WhereDialect(filter, MySQL, 0)
+}
+
+// WhereDialect build the conditions of WHERE from the filter dto, conditions are joined by AND.
+// only the field with where tag or op tag is used, absent option and nil pointer are skipped, other fields are always used.
+//   - where tag: raw condition, every ? is the value, like `where:"age >= ?"`, `where:"(name LIKE ? OR nick LIKE ?)"`.
+//   - op tag: eq, ne, gt, gte, lt, lte, like, in, nin, the column is from db tag, then form tag, then field name.
+//   - slice value: ? is expanded to `?, ?`, op default is in, only in and nin are allowed.
+//     empty slice is 1=0 for in, 1=1 for nin, and an error for where tag, because IN (NULL) is unknown for every row.
+//
+// offset is the count of args before, for PostgreSQL placeholder $n, like the len(args) of UpdateSet.
+// return empty string if no condition.
+func WhereDialect(filter any, dialect Dialect, offset int) (string, []any, error) {
+	v := reflect.ValueOf(filter)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil, fmt.Errorf("%w: %T", ErrOnlyStruct, filter)
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return "", nil, fmt.Errorf("%w: kind=%s", ErrOnlyStruct, v.Kind())
+	}
+
+	var conditions []string
+	var args []any
+	for _, field := range tagFields(v.Type(), "db", "form") {
+		where, hasWhere := field.Field.Tag.Lookup("where")
+		op, hasOp := field.Field.Tag.Lookup("op")
+		if !hasWhere && !hasOp {
+			continue
+		}
+		value, ok := whereValue(v.FieldByIndex(field.Index))
+		if !ok {
+			continue
+		}
+		isSlice := (value.Kind() == reflect.Slice || value.Kind() == reflect.Array) && value.Type().Elem().Kind() != reflect.Uint8
+		if !hasWhere {
+			if op == "" {
+				op = "eq"
+				if isSlice {
+					op = "in"
+				}
+			}
+			operator, ok := whereOperators[op]
+			if !ok {
+				return "", nil, fmt.Errorf("%w: op %q of %s", ErrNotSupportKind, op, field.Field.Name)
+			}
+			if isSlice && operator != "IN" && operator != "NOT IN" {
+				return "", nil, fmt.Errorf("%w: op %q of slice %s", ErrNotSupportKind, op, field.Field.Name)
+			}
+			if isSlice && value.Len() == 0 {
+				// IN () is invalid, IN (NULL) is unknown for every row.
+				if operator == "IN" {
+					conditions = append(conditions, "1=0")
+				} else {
+					conditions = append(conditions, "1=1")
+				}
+				continue
+			}
+			if operator == "IN" || operator == "NOT IN" {
+				where = field.Name + " " + operator + " (?)"
+			} else {
+				where = field.Name + " " + operator + " ?"
+			}
+		}
+
+		if isSlice && value.Len() == 0 {
+			// the where tag is raw sql, can not know IN or NOT IN, and IN (NULL) is unknown for every row.
+			return "", nil, fmt.Errorf("%w: empty slice %s of where tag, use op tag instead", ErrNotSupportKind, field.Field.Name)
+		}
+		var placeholders func() string
+		if isSlice {
+			placeholders = func() string {
+				items := make([]string, value.Len())
+				for i := range value.Len() {
+					args = append(args, value.Index(i).Interface())
+					items[i] = dialect.Placeholder(offset + len(args))
+				}
+				return strings.Join(items, ", ")
+			}
+		} else {
+			placeholders = func() string {
+				args = append(args, value.Interface())
+				return dialect.Placeholder(offset + len(args))
+			}
+		}
+		parts := strings.Split(where, "?")
+		var condition strings.Builder
+		for i, part := range parts {
+			if i > 0 {
+				condition.WriteString(placeholders())
+			}
+			condition.WriteString(part)
+		}
+		conditions = append(conditions, wrapWhereCondition(condition.String()))
+	}
+	return strings.Join(conditions, " AND "), args, nil
+}
+
+// whereValue unwrap option and pointer, return false if absent or nil.
+func whereValue(v reflect.Value) (reflect.Value, bool) {
+	if info, ok := OptionOf(v.Type()); ok {
+		value, ok := info.get(v)
+		if !ok {
+			return reflect.Value{}, false
+		}
+		v = value
+	}
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	return v, true
+}
+
+// wrapWhereCondition wrap the condition which has OR, so it can be joined by AND.
+func wrapWhereCondition(condition string) string {
+	if !strings.Contains(strings.ToUpper(condition), " OR ") {
+		return condition
+	}
+	// already wrapped if the first ( is closed by the last )
+	depth := 0
+	for i, c := range condition {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		if depth == 0 {
+			if i == len(condition)-1 && c == ')' {
+				return condition
+			}
+			return "(" + condition + ")"
+		}
+	}
+	return "(" + condition + ")"
+}
@@ -0,0 +1,117 @@
+package mox
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+)
+
+type SQLUpdateDto struct {
+	Name      mo.Option[string]     `db:"name"`
+	Age       mo.Option[int]        `db:"age"`
+	Nick      mo.Option[*string]    `db:"nick_name"`
+	DeletedAt mo.Option[*time.Time] `db:"deleted_at"`
+	NoTag     mo.Option[string]
+	Ignored   mo.Option[string] `db:"-"`
+	NotOption string            `db:"not_option"`
+}
+
+func TestUpdateSet(t *testing.T) {
+	dto := SQLUpdateDto{
+		Name:      mo.Some("sb"),
+		Age:       mo.Some(0),
+		DeletedAt: mo.Some[*time.Time](nil),
+		NoTag:     mo.Some("x"),
+		Ignored:   mo.Some("x"),
+		NotOption: "x",
+	}
+	datas := []struct {
+		dialect Dialect
+		sql     string
+	}{
+		{dialect: MySQL, sql: "name = ?, age = ?, deleted_at = NULL"},
+		{dialect: SQLite, sql: "name = ?, age = ?, deleted_at = NULL"},
+		{dialect: PostgreSQL, sql: "name = $1, age = $2, deleted_at = NULL"},
+	}
+	for _, data := range datas {
+		sql, args, err := UpdateSet(&dto, data.dialect)
+		require.NoError(t, err)
+		require.Equal(t, data.sql, sql)
+		require.Equal(t, []any{"sb", 0}, args)
+	}
+
+	nick := "nick"
+	sql, args, err := UpdateSet(SQLUpdateDto{Nick: mo.Some(&nick)}, PostgreSQL)
+	require.NoError(t, err)
+	require.Equal(t, "nick_name = $1", sql)
+	require.Equal(t, []any{&nick}, args)
+
+	_, _, err = UpdateSet(SQLUpdateDto{NoTag: mo.Some("x")}, MySQL)
+	require.ErrorIs(t, err, ErrNoUpdateColumn)
+	_, _, err = UpdateSet(1, MySQL)
+	require.ErrorIs(t, err, ErrOnlyStruct)
+}
+
+func TestWhere(t *testing.T) {
+	type Filter struct {
+		Name      mo.Option[string]   `form:"name" where:"(name LIKE ? OR nick_name LIKE ?)"`
+		MinAge    mo.Option[int]      `form:"min_age" db:"age" op:"gte"`
+		MaxAge    mo.Option[int]      `form:"max_age" db:"age" op:"lt"`
+		IDs       mo.Option[[]int64]  `form:"ids" db:"id" op:""`
+		Status    mo.Option[[]string] `form:"status" op:"nin"`
+		Email     *string             `form:"email" op:"eq"`
+		TenantID  int                 `db:"tenant_id" where:"tenant_id = ?"`
+		CreatedAt mo.Option[[]int]    `where:"created_at IN (?) OR created_at IS NULL"`
+		Page      mo.Option[int]      `form:"page"`
+	}
+	sql, args, err := Where(Filter{TenantID: 1, Page: mo.Some(1)})
+	require.NoError(t, err)
+	require.Equal(t, "tenant_id = ?", sql)
+	require.Equal(t, []any{1}, args)
+
+	email := "e"
+	filter := Filter{
+		Name:      mo.Some("%sb%"),
+		MinAge:    mo.Some(18),
+		MaxAge:    mo.Some(60),
+		IDs:       mo.Some([]int64{1, 2}),
+		Status:    mo.Some([]string{}),
+		Email:     &email,
+		TenantID:  1,
+		CreatedAt: mo.Some([]int{3}),
+	}
+	sql, args, err = Where(&filter)
+	require.NoError(t, err)
+	require.Equal(t, "(name LIKE ? OR nick_name LIKE ?) AND age >= ? AND age < ? AND id IN (?, ?) AND 1=1 AND email = ? AND tenant_id = ? AND (created_at IN (?) OR created_at IS NULL)", sql)
+	require.Equal(t, []any{"%sb%", "%sb%", 18, 60, int64(1), int64(2), "e", 1, 3}, args)
+
+	sql, args, err = Where(Filter{IDs: mo.Some([]int64{})})
+	require.NoError(t, err)
+	require.Equal(t, "1=0 AND tenant_id = ?", sql)
+	require.Equal(t, []any{0}, args)
+	_, _, err = Where(Filter{CreatedAt: mo.Some([]int{})})
+	require.ErrorIs(t, err, ErrNotSupportKind)
+	_, _, err = Where(struct {
+		IDs []int64 `db:"id" op:"eq"`
+	}{IDs: []int64{1, 2}})
+	require.ErrorIs(t, err, ErrNotSupportKind)
+
+	set, setArgs, err := UpdateSet(SQLUpdateDto{Name: mo.Some("sb")}, PostgreSQL)
+	require.NoError(t, err)
+	sql, args, err = WhereDialect(Filter{MinAge: mo.Some(18), IDs: mo.Some([]int64{1, 2})}, PostgreSQL, len(setArgs))
+	require.NoError(t, err)
+	require.Equal(t, "name = $1", set)
+	require.Equal(t, "age >= $2 AND id IN ($3, $4) AND tenant_id = $5", sql)
+	require.Equal(t, []any{18, int64(1), int64(2), 0}, args)
+
+	require.Equal(t, "(a = ? OR b = ?)", wrapWhereCondition("(a = ? OR b = ?)"))
+	require.Equal(t, "((a = ?) OR (b = ?))", wrapWhereCondition("(a = ?) OR (b = ?)"))
+	require.Equal(t, "(a = ? OR b = ?)", wrapWhereCondition("a = ? OR b = ?"))
+
+	_, _, err = Where(struct {
+		V mo.Option[int] `op:"unknown"`
+	}{V: mo.Some(1)})
+	require.ErrorIs(t, err, ErrNotSupportKind)
+}
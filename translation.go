@@ -0,0 +1,91 @@
+package mox
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	zhTranslations "github.com/go-playground/validator/v10/translations/zh"
+)
+
+// gpTranslations the messages of mox tags by language, {0} is the field, {1} is the param, {0} must be before {1}.
+var gpTranslations = map[string]map[string]string{
+	"en": {
+		"present":              "{0} must be present",
+		"notnil":               "{0} must not be null",
+		"present_with":         "{0} must be present when {1} is present",
+		"present_without":      "{0} must be present when {1} is absent",
+		"present_if":           "{0} must be present",
+		"absent_with":          "{0} must be absent when {1} is present",
+		"absent_without":       "{0} must be absent when {1} is absent",
+		"excluded_if_present":  "{0} must be absent",
+		"exactly_one_present":  "{0}: exactly one of {1} must be present",
+		"at_least_one_present": "{0}: at least one of {1} must be present",
+	},
+	"zh": {
+		"present":              "{0}必须提供",
+		"notnil":               "{0}不能为null",
+		"present_with":         "{0}在{1}提供时必须提供",
+		"present_without":      "{0}在{1}未提供时必须提供",
+		"present_if":           "{0}必须提供",
+		"absent_with":          "{0}在{1}提供时不能提供",
+		"absent_without":       "{0}在{1}未提供时不能提供",
+		"excluded_if_present":  "{0}不能提供",
+		"exactly_one_present":  "{0}：{1}必须且只能提供一个",
+		"at_least_one_present": "{0}：{1}至少提供一个",
+	},
+}
+
+// RegisterGPTranslations register the messages of mox tags for trans, support en and zh, other locales use en.
+func RegisterGPTranslations(validate *validator.Validate, trans ut.Translator) error {
+	language, _, _ := strings.Cut(trans.Locale(), "_")
+	messages, ok := gpTranslations[language]
+	if !ok {
+		messages = gpTranslations["en"]
+	}
+	for tag, message := range messages {
+		err := validate.RegisterTranslation(tag, trans, func(trans ut.Translator) error {
+			return trans.Add(tag, message, true)
+		}, gpTranslate)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func gpTranslate(trans ut.Translator, fe validator.FieldError) string {
+	message, err := trans.T(fe.Tag(), fe.Field(), fe.Param())
+	if err != nil {
+		return fe.Error()
+	}
+	return message
+}
+
+// RegisterGPDefaultTranslations register the standard translations and mox translations of locale, en or zh.
+// return the translator for ValidationErrors.Translate.
+func RegisterGPDefaultTranslations(validate *validator.Validate, locale string) (ut.Translator, error) {
+	uni := ut.New(en.New(), en.New(), zh.New())
+	trans, ok := uni.GetTranslator(locale)
+	if !ok {
+		return nil, fmt.Errorf("%w: locale %s", ErrNotSupportKind, locale)
+	}
+	var err error
+	switch trans.Locale() {
+	case "zh":
+		err = zhTranslations.RegisterDefaultTranslations(validate, trans)
+	default:
+		err = enTranslations.RegisterDefaultTranslations(validate, trans)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := RegisterGPTranslations(validate, trans); err != nil {
+		return nil, err
+	}
+	return trans, nil
+}
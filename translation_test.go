@@ -0,0 +1,60 @@
+package mox
+
+import (
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+)
+
+type TranslationDto struct {
+	Age  mo.Option[int]    `validate:"present"`
+	Name mo.Option[string] `validate:"notnil"`
+	Nick string            `validate:"required"`
+}
+
+type TranslationGroupDto struct {
+	ID    mo.Option[int64]
+	Email mo.Option[string]
+	Phone mo.Option[string] `validate:"absent_without=Email"`
+}
+
+func TestGPTranslations(t *testing.T) {
+	for locale, expected := range map[string]map[string]string{
+		"en": {
+			"TranslationDto.Age":  "Age must be present",
+			"TranslationDto.Name": "Name must not be null",
+			"TranslationDto.Nick": "Nick is a required field",
+		},
+		"zh": {
+			"TranslationDto.Age":  "Age必须提供",
+			"TranslationDto.Name": "Name不能为null",
+			"TranslationDto.Nick": "Nick为必填字段",
+		},
+	} {
+		validate := validator.New()
+		require.NoError(t, RegisterGPValidatorPresent(validate))
+		require.NoError(t, RegisterGPValidatorNotNil(validate))
+		trans, err := RegisterGPDefaultTranslations(validate, locale)
+		require.NoError(t, err)
+
+		err = validate.Struct(TranslationDto{})
+		require.Error(t, err)
+		require.Equal(t, expected, map[string]string(err.(validator.ValidationErrors).Translate(trans)), locale)
+	}
+
+	validate := validator.New()
+	require.NoError(t, RegisterGPValidatorPresence(validate))
+	validate.RegisterStructValidation(GPExactlyOnePresent("ID", "Email"), TranslationGroupDto{})
+	trans, err := RegisterGPDefaultTranslations(validate, "zh")
+	require.NoError(t, err)
+	err = validate.Struct(TranslationGroupDto{Phone: mo.Some("123")})
+	require.Equal(t, validator.ValidationErrorsTranslations{
+		"TranslationGroupDto.ID":    "ID：ID Email必须且只能提供一个",
+		"TranslationGroupDto.Phone": "Phone在Email未提供时不能提供",
+	}, err.(validator.ValidationErrors).Translate(trans))
+
+	_, err = RegisterGPDefaultTranslations(validator.New(), "fr")
+	require.ErrorIs(t, err, ErrNotSupportKind)
+}
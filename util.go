@@ -3,13 +3,232 @@ package mox
 import (
 	"reflect"
 	"strings"
+	"unsafe"
 )
 
+const moPkgPath = "github.com/samber/mo"
+
+// OptionInfo describe a type which is recognized as mo.Option.
+type OptionInfo struct {
+	// Type the inspected type.
+	Type reflect.Type
+	// Elem the T of mo.Option[T].
+	Elem reflect.Type
+	// Pointer Type is a pointer to the option, like *mo.Option[T].
+	Pointer bool
+	// Index the embedded field path to the mo.Option layout, empty if not embedded.
+	Index []int
+}
+
+// OptionOf detect whether ot is an option, support:
+//   - mo.Option[T]
+//   - *mo.Option[T]
+//   - defined type, like `type MaybeName mo.Option[string]`
+//   - struct only embedding an option, like `type Name struct{ mo.Option[string] }`
+func OptionOf(ot reflect.Type) (OptionInfo, bool) {
+	if ot == nil {
+		return OptionInfo{}, false
+	}
+	info := OptionInfo{Type: ot}
+	t := ot
+	if t.Kind() == reflect.Ptr {
+		info.Pointer = true
+		t = t.Elem()
+	}
+	for t.Kind() == reflect.Struct {
+		if elem, ok := optionLayout(t); ok {
+			info.Elem = elem
+			return info, true
+		}
+		if t.NumField() != 1 || !t.Field(0).Anonymous {
+			break
+		}
+		info.Index = append(info.Index, 0)
+		t = t.Field(0).Type
+	}
+	return OptionInfo{}, false
+}
+
+// IsOption see OptionOf
 func IsOption(ot reflect.Type) bool {
-	pkg := ot.PkgPath()
-	typeName := ot.Name()
-	if pkg == "github.com/samber/mo" && strings.HasPrefix(typeName, "Option[") {
-		return true
+	_, ok := OptionOf(ot)
+	return ok
+}
+
+// optionLayout check the struct has the same fields with mo.Option, defined type will keep it.
+func optionLayout(t reflect.Type) (reflect.Type, bool) {
+	if t.NumField() != 2 {
+		return nil, false
+	}
+	isPresent, value := t.Field(0), t.Field(1)
+	if isPresent.Name != "isPresent" || isPresent.PkgPath != moPkgPath || isPresent.Type.Kind() != reflect.Bool {
+		return nil, false
+	}
+	if value.Name != "value" || value.PkgPath != moPkgPath {
+		return nil, false
+	}
+	return value.Type, true
+}
+
+// fields return the settable isPresent and value of the option v.
+// when alloc=true, nil pointer will be allocated, else return ok=false.
+func (info OptionInfo) fields(v reflect.Value, alloc bool) (isPresent reflect.Value, value reflect.Value, ok bool) {
+	if info.Pointer {
+		if v.IsNil() {
+			if !alloc || !v.CanSet() {
+				return reflect.Value{}, reflect.Value{}, false
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if len(info.Index) > 0 {
+		v = v.FieldByIndex(info.Index)
+	}
+	if !v.CanAddr() {
+		// for can not get address of unaddressable value, so copy it.
+		if !v.CanInterface() {
+			return reflect.Value{}, reflect.Value{}, false
+		}
+		tmp := reflect.New(v.Type()).Elem()
+		tmp.Set(v)
+		v = tmp
+	}
+	isPresentField, valueField := v.Field(0), v.Field(1)
+	isPresent = reflect.NewAt(isPresentField.Type(), unsafe.Pointer(isPresentField.UnsafeAddr())).Elem()
+	value = reflect.NewAt(valueField.Type(), unsafe.Pointer(valueField.UnsafeAddr())).Elem()
+	return isPresent, value, true
+}
+
+// isPresent report whether the option v has a value, nil pointer is absent.
+func (info OptionInfo) isPresent(v reflect.Value) bool {
+	isPresent, _, ok := info.fields(v, false)
+	return ok && isPresent.Bool()
+}
+
+// get return the value of option v and whether it is present.
+func (info OptionInfo) get(v reflect.Value) (reflect.Value, bool) {
+	isPresent, value, ok := info.fields(v, false)
+	if !ok || !isPresent.Bool() {
+		return reflect.Zero(info.Elem), false
+	}
+	return value, true
+}
+
+// set make the option v Some(value), v must be settable.
+func (info OptionInfo) set(v reflect.Value, value reflect.Value) {
+	isPresent, optionValue, ok := info.fields(v, true)
+	if !ok {
+		return
+	}
+	if value.Type() != info.Elem {
+		value = value.Convert(info.Elem)
+	}
+	optionValue.Set(value)
+	isPresent.SetBool(true)
+}
+
+// unset make the option v None, v must be settable.
+func (info OptionInfo) unset(v reflect.Value) {
+	isPresent, optionValue, ok := info.fields(v, false)
+	if !ok {
+		return
+	}
+	optionValue.Set(reflect.Zero(info.Elem))
+	isPresent.SetBool(false)
+}
+
+// tagField the exported struct field with its name from tag, like encoding/json.
+type tagField struct {
+	Field reflect.StructField
+	// Index the full index path, include the embedded struct.
+	Index []int
+	// Name the tag name, or Field.Name if the tag has no name.
+	Name string
+	// Options the tag options after name.
+	Options []string
+}
+
+// tagFields collect the exported fields of struct t by tag keys, the name is from the first key which has a name.
+// the key with tag "-" is ignored, the field will be skipped if all tags of keys are "-".
+// the embedded struct without tag name will be flattened like encoding/json, but not option.
+func tagFields(t reflect.Type, keys ...string) []tagField {
+	return appendTagFields(nil, t, keys, nil)
+}
+
+func appendTagFields(fields []tagField, t reflect.Type, keys []string, index []int) []tagField {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, options, skip := fieldTagName(field, keys)
+		if skip {
+			continue
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct && !IsOption(field.Type) {
+			fields = appendTagFields(fields, field.Type, keys, fieldIndex)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, tagField{
+			Field:   field,
+			Index:   fieldIndex,
+			Name:    name,
+			Options: options,
+		})
+	}
+	return fields
+}
+
+// fieldTagName return the name and options of the first key which has a name.
+func fieldTagName(field reflect.StructField, keys []string) (name string, options []string, skip bool) {
+	found, ignored := false, false
+	for _, key := range keys {
+		tag, ok := field.Tag.Lookup(key)
+		if !ok {
+			continue
+		}
+		if tag == "-" {
+			ignored = true
+			continue
+		}
+		tags := strings.Split(tag, ",")
+		if !found {
+			found = true
+			options = tags[1:]
+		}
+		if name = strings.TrimSpace(tags[0]); name != "" {
+			return name, tags[1:], false
+		}
+	}
+	return "", options, ignored && !found
+}
+
+// lookupTagField find the field by tag name, then by case-insensitive tag name like encoding/json.
+func lookupTagField(fields []tagField, name string) (tagField, bool) {
+	for _, field := range fields {
+		if field.Name == name {
+			return field, true
+		}
+	}
+	for _, field := range fields {
+		if strings.EqualFold(field.Name, name) {
+			return field, true
+		}
+	}
+	return tagField{}, false
+}
+
+// hasTagOption report whether the tag options contain option.
+func (f tagField) hasTagOption(option string) bool {
+	for _, o := range f.Options {
+		if strings.TrimSpace(o) == option {
+			return true
+		}
 	}
 	return false
 }
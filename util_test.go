@@ -0,0 +1,107 @@
+package mox
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+)
+
+type MaybeName mo.Option[string]
+
+type EmbedName struct {
+	mo.Option[string]
+}
+
+func TestOptionOf(t *testing.T) {
+	datas := []struct {
+		typ     reflect.Type
+		ok      bool
+		elem    reflect.Type
+		pointer bool
+	}{
+		{typ: reflect.TypeOf(mo.Option[string]{}), ok: true, elem: reflect.TypeOf("")},
+		{typ: reflect.TypeOf(&mo.Option[[]int]{}), ok: true, elem: reflect.TypeOf([]int{}), pointer: true},
+		{typ: reflect.TypeOf(MaybeName{}), ok: true, elem: reflect.TypeOf("")},
+		{typ: reflect.TypeOf(&MaybeName{}), ok: true, elem: reflect.TypeOf(""), pointer: true},
+		{typ: reflect.TypeOf(EmbedName{}), ok: true, elem: reflect.TypeOf("")},
+		{typ: reflect.TypeOf(mo.Either[string, int]{}), ok: false},
+		{typ: reflect.TypeOf(struct{ A, B string }{}), ok: false},
+		{typ: reflect.TypeOf(""), ok: false},
+	}
+	for _, data := range datas {
+		info, ok := OptionOf(data.typ)
+		require.Equal(t, data.ok, ok, data.typ.String())
+		require.Equal(t, data.ok, IsOption(data.typ), data.typ.String())
+		if !ok {
+			continue
+		}
+		require.Equal(t, data.elem, info.Elem, data.typ.String())
+		require.Equal(t, data.pointer, info.Pointer, data.typ.String())
+	}
+}
+
+func TestOptionInfoAccess(t *testing.T) {
+	var v struct {
+		Ptr   *mo.Option[int]
+		Name  MaybeName
+		Embed EmbedName
+	}
+	rv := reflect.ValueOf(&v).Elem()
+	for i := range rv.NumField() {
+		info, ok := OptionOf(rv.Field(i).Type())
+		require.True(t, ok)
+		require.False(t, info.isPresent(rv.Field(i)))
+	}
+
+	ptrInfo, _ := OptionOf(rv.Field(0).Type())
+	ptrInfo.set(rv.Field(0), reflect.ValueOf(1))
+	require.Equal(t, mo.Some(1), *v.Ptr)
+
+	nameInfo, _ := OptionOf(rv.Field(1).Type())
+	nameInfo.set(rv.Field(1), reflect.ValueOf("sb"))
+	require.Equal(t, MaybeName(mo.Some("sb")), v.Name)
+	value, ok := nameInfo.get(rv.Field(1))
+	require.True(t, ok)
+	require.Equal(t, "sb", value.Interface())
+	nameInfo.unset(rv.Field(1))
+	require.Equal(t, MaybeName(mo.None[string]()), v.Name)
+
+	embedInfo, _ := OptionOf(rv.Field(2).Type())
+	embedInfo.set(rv.Field(2), reflect.ValueOf("sb"))
+	require.Equal(t, mo.Some("sb"), v.Embed.Option)
+}
+
+func TestOptionOfUsage(t *testing.T) {
+	type Dto struct {
+		Ptr   *mo.Option[int] `form:"ptr" json:"ptr,omitempty" validate:"present"`
+		Name  MaybeName       `form:"name" json:"name,omitempty" validate:"present"`
+		Embed EmbedName       `form:"embed" json:"embed,omitempty" validate:"notnil"`
+	}
+
+	var dto Dto
+	req := httptest.NewRequest("GET", "/?ptr=1&name=sb&embed=e", nil)
+	require.NoError(t, OptionQueryBinding.Bind(req, &dto))
+	require.Equal(t, mo.Some(1), *dto.Ptr)
+	require.Equal(t, MaybeName(mo.Some("sb")), dto.Name)
+	require.Equal(t, mo.Some("e"), dto.Embed.Option)
+
+	validate := validator.New()
+	require.NoError(t, RegisterGPValidatorPresent(validate))
+	require.NoError(t, RegisterGPValidatorNotNil(validate))
+	require.NoError(t, validate.Struct(&dto))
+	require.ErrorContains(t, validate.Struct(&Dto{}), "failed on the 'present' tag")
+
+	jsoniterApi := jsoniter.Config{}.Froze()
+	jsoniterApi.RegisterExtension(&OptionExtension{})
+	b, err := jsoniterApi.Marshal(dto)
+	require.NoError(t, err)
+	require.Equal(t, `{"ptr":1,"name":"sb","embed":"e"}`, string(b))
+	b, err = jsoniterApi.Marshal(Dto{Ptr: &mo.Option[int]{}})
+	require.NoError(t, err)
+	require.Equal(t, `{}`, string(b))
+}
@@ -0,0 +1,266 @@
+package mox
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// Validator the binding.StructValidator with all mox tags, install it by `binding.Validator = mox.NewValidator()`.
+//   - tag is binding, same as gin.
+//   - tags: present, notnil, omitnone, present_with, present_without, present_if, absent_with, absent_without, excluded_if_present.
+//   - option types are unwrapped, the types used by the validated struct are discovered automatically.
+//   - the field name in error is from json tag, then form, uri, header tag, see GPTagNameFunc.
+//   - slice and array of structs are validated, the error is binding.SliceValidationError with the index of element.
+//   - scenario tag `<tag>_<scenario>`, like `binding_update:"omitnone,min=1"`, replace the rules of field in the scenario, see ValidateScenario.
+//   - the mox bindings validate with the request context, see ValidateStructCtx and RegisterGPContextValidator.
+type Validator struct {
+	tagName string
+	// mu guard the registration of engines, RegisterCustomTypeFunc is not safe with validating.
+	mu         sync.RWMutex
+	validate   *validator.Validate
+	scenarios  map[string]*validator.Validate
+	discovered map[reflect.Type]bool
+	configures []func(validate *validator.Validate)
+}
+
+var _ binding.StructValidator = (*Validator)(nil)
+
+func NewValidator() *Validator {
+	return NewValidatorWithTag("binding")
+}
+
+// NewValidatorWithTag create the Validator use the tagName, like validate.
+func NewValidatorWithTag(tagName string) *Validator {
+	v := &Validator{
+		tagName:    tagName,
+		scenarios:  map[string]*validator.Validate{},
+		discovered: map[reflect.Type]bool{},
+	}
+	v.validate = v.newEngine(tagName)
+	return v
+}
+
+func (v *Validator) newEngine(tagName string) *validator.Validate {
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	validate.SetTagName(tagName)
+	RegisterGPTagNameFunc(validate)
+	RegisterGPVOptionBuiltin(validate)
+	RegisterGPValidatorOmitNone(validate)
+	// the tags are not registered yet, so the errors are impossible
+	_ = RegisterGPValidatorPresent(validate)
+	_ = RegisterGPValidatorNotNil(validate)
+	_ = RegisterGPValidatorPresence(validate)
+	for t := range v.discovered {
+		RegisterGPVOptionOf(validate, t)
+	}
+	for _, configure := range v.configures {
+		configure(validate)
+	}
+	return validate
+}
+
+// Configure apply fn to the engines of all scenarios, use it to register custom validations instead of Engine.
+func (v *Validator) Configure(fn func(validate *validator.Validate)) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.configures = append(v.configures, fn)
+	fn(v.validate)
+	for _, validate := range v.scenarios {
+		fn(validate)
+	}
+}
+
+// ValidateStruct validate struct, pointer to struct, slice or array of them, other types are ignored.
+func (v *Validator) ValidateStruct(obj any) error {
+	return v.ValidateScenarioCtx(context.Background(), obj, "")
+}
+
+// ValidateStructCtx same as ValidateStruct, ctx is passed to the validator.FuncCtx validations.
+func (v *Validator) ValidateStructCtx(ctx context.Context, obj any) error {
+	return v.ValidateScenarioCtx(ctx, obj, "")
+}
+
+// ValidateScenario validate obj in the scenario, the field with scenario tag is validated by it instead of the tag,
+// the default scenario is empty. the nested field with scenario tag needs its parent fields also have scenario tag to dive into it.
+func (v *Validator) ValidateScenario(obj any, scenario string) error {
+	return v.ValidateScenarioCtx(context.Background(), obj, scenario)
+}
+
+// ValidateScenarioCtx same as ValidateScenario, ctx is passed to the validator.FuncCtx validations.
+func (v *Validator) ValidateScenarioCtx(ctx context.Context, obj any, scenario string) error {
+	if obj == nil {
+		return nil
+	}
+	value := reflect.ValueOf(obj)
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return nil
+		}
+		if value.Elem().Kind() != reflect.Struct {
+			return v.ValidateScenarioCtx(ctx, value.Elem().Interface(), scenario)
+		}
+	case reflect.Struct:
+	case reflect.Slice, reflect.Array:
+		errs := make(binding.SliceValidationError, value.Len())
+		failed := false
+		for i := range value.Len() {
+			if errs[i] = v.ValidateScenarioCtx(ctx, value.Index(i).Interface(), scenario); errs[i] != nil {
+				failed = true
+			}
+		}
+		if !failed {
+			return nil
+		}
+		return errs
+	default:
+		return nil
+	}
+
+	v.discover(value.Type())
+	if scenario == "" {
+		v.mu.RLock()
+		defer v.mu.RUnlock()
+		return v.validate.StructCtx(ctx, obj)
+	}
+	scenarioTag := v.tagName + "_" + scenario
+	scenarioValidate := v.engine(scenarioTag)
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	err := v.validate.StructFilteredCtx(ctx, obj, scenarioFilter(reflect.Indirect(value).Type(), scenarioTag))
+	return mergeValidationErrors(err, scenarioValidate.StructCtx(ctx, obj))
+}
+
+// discover register the option types used by t.
+func (v *Validator) discover(t reflect.Type) {
+	v.mu.RLock()
+	ok := v.discovered[t]
+	v.mu.RUnlock()
+	if ok {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.discovered[t] {
+		return
+	}
+	RegisterGPVOptionOf(v.validate, t)
+	for _, validate := range v.scenarios {
+		RegisterGPVOptionOf(validate, t)
+	}
+	v.discovered[t] = true
+}
+
+// engine return the engine of scenario tag.
+func (v *Validator) engine(scenarioTag string) *validator.Validate {
+	v.mu.RLock()
+	validate, ok := v.scenarios[scenarioTag]
+	v.mu.RUnlock()
+	if ok {
+		return validate
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if validate, ok := v.scenarios[scenarioTag]; ok {
+		return validate
+	}
+	validate = v.newEngine(scenarioTag)
+	v.scenarios[scenarioTag] = validate
+	return validate
+}
+
+// Engine return the *validator.Validate of default scenario.
+func (v *Validator) Engine() any {
+	return v.validate
+}
+
+// scenarioFilter skip the field which has scenario tag, the namespace is like Dto.Items[0].Name.
+func scenarioFilter(root reflect.Type, scenarioTag string) validator.FilterFunc {
+	return func(ns []byte) bool {
+		parts := strings.Split(string(ns), ".")
+		if root.Name() != "" {
+			// the first part is the name of root struct
+			parts = parts[1:]
+		}
+		t := root
+		var field reflect.StructField
+		for _, part := range parts {
+			name, _, _ := strings.Cut(part, "[")
+			var ok bool
+			if field, ok = derefType(t).FieldByName(name); !ok {
+				return false
+			}
+			t = field.Type
+			for range strings.Count(part, "[") {
+				switch elem := derefType(t); elem.Kind() {
+				case reflect.Slice, reflect.Array, reflect.Map:
+					t = elem.Elem()
+				}
+			}
+		}
+		_, ok := field.Tag.Lookup(scenarioTag)
+		return ok
+	}
+}
+
+// mergeValidationErrors merge the validator.ValidationErrors, return the first error which is not.
+// the struct level validations run in every engine, so the errors with same namespace and tag are merged as one.
+func mergeValidationErrors(errs ...error) error {
+	var merged validator.ValidationErrors
+	seen := map[[2]string]bool{}
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		var validationErrors validator.ValidationErrors
+		if !errors.As(err, &validationErrors) {
+			return err
+		}
+		for _, fieldError := range validationErrors {
+			key := [2]string{fieldError.Namespace(), fieldError.Tag()}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, fieldError)
+		}
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
+
+// ValidateScenario validate obj in the scenario by binding.Validator, see ValidateScenarioCtx.
+func ValidateScenario(obj any, scenario string) error {
+	return ValidateScenarioCtx(context.Background(), obj, scenario)
+}
+
+// ValidateScenarioCtx validate obj in the scenario with ctx by binding.Validator, see Validator.ValidateScenarioCtx.
+// binding.Validator which does not support scenario validate obj by ValidateStructCtx, or ValidateStruct without ctx.
+func ValidateScenarioCtx(ctx context.Context, obj any, scenario string) error {
+	switch v := binding.Validator.(type) {
+	case nil:
+		return nil
+	case interface {
+		ValidateScenarioCtx(ctx context.Context, obj any, scenario string) error
+	}:
+		return v.ValidateScenarioCtx(ctx, obj, scenario)
+	case interface {
+		ValidateScenario(obj any, scenario string) error
+	}:
+		return v.ValidateScenario(obj, scenario)
+	case interface {
+		ValidateStructCtx(ctx context.Context, obj any) error
+	}:
+		return v.ValidateStructCtx(ctx, obj)
+	default:
+		return v.ValidateStruct(obj)
+	}
+}
@@ -0,0 +1,266 @@
+package mox
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/require"
+)
+
+type ValidatorItem struct {
+	Name  mo.Option[string]  `json:"name" binding:"present,min=2"`
+	Score mo.Option[float32] `json:"score" binding:"omitnone,max=100"`
+}
+
+type ValidatorDto struct {
+	Page   mo.Option[int]                `form:"page" binding:"omitnone,min=1"`
+	Kind   mo.Option[string]             `form:"kind" binding:"omitnone,oneof=a b"`
+	Item   mo.Option[ValidatorItem]      `form:"-" json:"item"`
+	Items  mo.Option[[]ValidatorItem]    `json:"items" binding:"omitnone,dive"`
+	Tags   mo.Option[map[string]float32] `json:"tags" binding:"omitnone,dive,keys,min=2,endkeys,gt=0"`
+	Secret string                        `json:"-" binding:"max=1"`
+}
+
+func TestValidator(t *testing.T) {
+	v := NewValidator()
+	require.IsType(t, &validator.Validate{}, v.Engine())
+	require.NoError(t, v.ValidateStruct(nil))
+	require.NoError(t, v.ValidateStruct(1))
+	require.NoError(t, v.ValidateStruct(&ValidatorDto{}))
+
+	err := v.ValidateStruct(&ValidatorDto{
+		Page:   mo.Some(0),
+		Kind:   mo.Some("c"),
+		Item:   mo.Some(ValidatorItem{}),
+		Items:  mo.Some([]ValidatorItem{{Name: mo.Some("sb"), Score: mo.Some[float32](101)}}),
+		Tags:   mo.Some(map[string]float32{"a": 1}),
+		Secret: "ab",
+	})
+	require.Error(t, err)
+	var namespaces []string
+	for _, fe := range err.(validator.ValidationErrors) {
+		namespaces = append(namespaces, fe.Namespace()+" "+fe.Tag())
+	}
+	require.ElementsMatch(t, []string{
+		"ValidatorDto.page min",
+		"ValidatorDto.kind oneof",
+		"ValidatorDto.item.name present",
+		"ValidatorDto.items[0].score max",
+		"ValidatorDto.tags[a] min",
+		"ValidatorDto.Secret max",
+	}, namespaces)
+
+	err = v.ValidateStruct([]*ValidatorItem{{Name: mo.Some("sb")}, {Name: mo.Some("s")}, nil})
+	require.Equal(t, binding.SliceValidationError{nil, err.(binding.SliceValidationError)[1], nil}, err)
+	require.ErrorContains(t, err, "[1]: Key: 'ValidatorItem.name' Error:Field validation for 'name' failed on the 'min' tag")
+	problem := NewProblem(err)
+	require.Equal(t, "[1].name", problem.Errors[0].Field)
+	require.NoError(t, v.ValidateStruct([2]ValidatorItem{{Name: mo.Some("sb")}, {Name: mo.Some("sb")}}))
+}
+
+func TestValidatorBinding(t *testing.T) {
+	defaultValidator := binding.Validator
+	defer func() { binding.Validator = defaultValidator }()
+	binding.Validator = NewValidator()
+
+	engine := gin.New()
+	engine.GET("/validate", func(c *gin.Context) {
+		var value ValidatorDto
+		if err := c.ShouldBindWith(&value, OptionQueryBinding); err != nil {
+			Problem(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/validate?page=1&kind=a", nil))
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/validate?page=0", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), `"field":"page","tag":"min","param":"1","code":"min"`)
+}
+
+type ScenarioItem struct {
+	Name mo.Option[string] `validate:"present,min=2" validate_update:"omitnone,min=2"`
+}
+
+type ScenarioDto struct {
+	Name  mo.Option[string]         `form:"name" validate:"present" validate_update:"omitnone,min=1"`
+	Age   mo.Option[int]            `form:"age" validate:"omitnone,min=1"`
+	Kind  mo.Option[string]         `form:"kind" validate_update:"present"`
+	Items mo.Option[[]ScenarioItem] `form:"-" validate:"omitnone,dive" validate_update:"omitnone,dive"`
+}
+
+func TestValidatorScenario(t *testing.T) {
+	v := NewValidatorWithTag("validate")
+	require.NoError(t, v.ValidateScenario(&ScenarioDto{Name: mo.Some("")}, ""))
+	require.NoError(t, v.ValidateScenario(&ScenarioDto{Kind: mo.Some("a")}, "update"))
+
+	errorTags := func(err error) []string {
+		var tags []string
+		for _, fe := range err.(validator.ValidationErrors) {
+			tags = append(tags, fe.Namespace()+" "+fe.Tag())
+		}
+		return tags
+	}
+	err := v.ValidateScenario(&ScenarioDto{Age: mo.Some(0), Items: mo.Some([]ScenarioItem{{}})}, "")
+	require.ElementsMatch(t, []string{
+		"ScenarioDto.name present",
+		"ScenarioDto.age min",
+		"ScenarioDto.Items[0].Name present",
+	}, errorTags(err))
+	err = v.ValidateScenario(&ScenarioDto{Name: mo.Some(""), Age: mo.Some(0), Items: mo.Some([]ScenarioItem{{}, {Name: mo.Some("a")}})}, "update")
+	require.ElementsMatch(t, []string{
+		"ScenarioDto.name min",
+		"ScenarioDto.age min",
+		"ScenarioDto.kind present",
+		"ScenarioDto.Items[1].Name min",
+	}, errorTags(err))
+
+	err = v.ValidateScenario([]ScenarioDto{{Kind: mo.Some("a")}, {}}, "update")
+	require.Equal(t, []string{"ScenarioDto.kind present"}, errorTags(err.(binding.SliceValidationError)[1]))
+
+	// custom validations are registered to all scenarios
+	v.Configure(func(validate *validator.Validate) {
+		require.NoError(t, validate.RegisterValidation("even", func(fl validator.FieldLevel) bool {
+			return fl.Field().Int()%2 == 0
+		}))
+	})
+	type EvenDto struct {
+		V mo.Option[int] `validate:"omitnone,even" validate_create:"present,even"`
+	}
+	require.NoError(t, v.ValidateScenario(EvenDto{}, ""))
+	require.ErrorContains(t, v.ValidateScenario(EvenDto{V: mo.Some(1)}, ""), "'even' tag")
+	require.ErrorContains(t, v.ValidateScenario(EvenDto{}, "create"), "'present' tag")
+	require.ErrorContains(t, v.ValidateScenario(EvenDto{V: mo.Some(1)}, "create"), "'even' tag")
+}
+
+type ScenarioGroupDto struct {
+	ID    mo.Option[int64]  `json:"id"`
+	Email mo.Option[string] `json:"email" validate_update:"omitnone,email"`
+}
+
+func TestValidatorScenarioStructLevel(t *testing.T) {
+	v := NewValidatorWithTag("validate")
+	v.Configure(func(validate *validator.Validate) {
+		validate.RegisterStructValidation(GPExactlyOnePresent("ID", "Email"), ScenarioGroupDto{})
+	})
+	for _, scenario := range []string{"", "update"} {
+		err := v.ValidateScenario(&ScenarioGroupDto{}, scenario)
+		var validationErrors validator.ValidationErrors
+		require.ErrorAs(t, err, &validationErrors)
+		require.Len(t, validationErrors, 1, scenario)
+		require.Equal(t, "exactly_one_present", validationErrors[0].Tag())
+	}
+	err := v.ValidateScenario(&ScenarioGroupDto{Email: mo.Some("a")}, "update")
+	require.Len(t, err.(validator.ValidationErrors), 1)
+	require.Equal(t, "email", err.(validator.ValidationErrors)[0].Tag())
+}
+
+func TestValidatorScenarioBinding(t *testing.T) {
+	defaultValidator := binding.Validator
+	defer func() { binding.Validator = defaultValidator }()
+	binding.Validator = NewValidatorWithTag("validate")
+
+	engine := gin.New()
+	engine.GET("/query", func(c *gin.Context) {
+		var value ScenarioDto
+		if err := c.ShouldBindWith(&value, OptionQueryBinding.Scenario("update")); err != nil {
+			Problem(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+	engine.POST("/users", HandleScenario("create", func(ctx context.Context, req ScenarioDto) (ScenarioDto, error) {
+		return req, nil
+	}))
+	engine.PATCH("/users", HandleScenario("update", func(ctx context.Context, req ScenarioDto) (ScenarioDto, error) {
+		return req, nil
+	}))
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+	require.Equal(t, http.StatusNoContent, serve(http.MethodGet, "/query?kind=a").Code)
+	require.Equal(t, http.StatusBadRequest, serve(http.MethodGet, "/query?name=a").Code)
+	require.Equal(t, http.StatusOK, serve(http.MethodPost, "/users?name=a").Code)
+	require.Equal(t, http.StatusBadRequest, serve(http.MethodPost, "/users?kind=a").Code)
+	require.Equal(t, http.StatusOK, serve(http.MethodPatch, "/users?kind=a").Code)
+	require.Equal(t, http.StatusBadRequest, serve(http.MethodPatch, "/users?name=a").Code)
+}
+
+type ValidatorContextDto struct {
+	ID     int    `uri:"id" form:"-"`
+	Tenant string `form:"tenant" binding:"required,tenant"`
+}
+
+func TestValidatorContext(t *testing.T) {
+	defaultValidator := binding.Validator
+	defer func() { binding.Validator = defaultValidator }()
+	v := NewValidator()
+	v.Configure(func(validate *validator.Validate) {
+		require.NoError(t, RegisterGPContextValidator(validate, "tenant", "tenant", func(fl validator.FieldLevel, tenant string) bool {
+			return fl.Field().String() == tenant
+		}))
+	})
+	binding.Validator = v
+
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		c.Set("tenant", c.GetHeader("X-Tenant"))
+	}, ContextMiddleware())
+	engine.GET("/query", func(c *gin.Context) {
+		var value ValidatorContextDto
+		if err := c.ShouldBindWith(&value, OptionQueryBinding); err != nil {
+			Problem(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+	engine.GET("/scenario", func(c *gin.Context) {
+		var value ValidatorContextDto
+		if err := c.ShouldBindWith(&value, OptionQueryBinding.Scenario("update")); err != nil {
+			Problem(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+	engine.GET("/uri/:id", func(c *gin.Context) {
+		value := ValidatorContextDto{Tenant: c.Query("tenant")}
+		if err := ShouldBindGinUri(c, &value); err != nil {
+			Problem(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+	engine.GET("/handle", Handle(func(ctx context.Context, req ValidatorContextDto) (ValidatorContextDto, error) {
+		return req, nil
+	}))
+
+	for _, path := range []string{"/query", "/scenario", "/uri/1", "/handle"} {
+		serve := func(tenant, header string) int {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, path+"?tenant="+tenant, nil)
+			req.Header.Set("X-Tenant", header)
+			engine.ServeHTTP(w, req)
+			return w.Code
+		}
+		successCode := http.StatusNoContent
+		if path == "/handle" {
+			successCode = http.StatusOK
+		}
+		require.Equal(t, successCode, serve("a", "a"), path)
+		require.Equal(t, http.StatusBadRequest, serve("a", "b"), path)
+	}
+}